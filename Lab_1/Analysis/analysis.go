@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"classic"
+)
+
+// Статистический анализ шифртекста: латиница и кириллица разбираются
+// отдельными потоками, как в программах шифрования Lab_1.
+
+const (
+	barWidth     = 40 // ширина столбца гистограммы для самой частой буквы
+	topBigrams   = 20 // сколько самых частых биграмм показывать
+	topRepeats   = 12 // сколько повторов n-грамм показывать
+	maxPeriod    = 20 // наибольший период, для которого считаются делители расстояний
+	minLetters   = 40 // меньше букв — вывод о типе шифра ненадёжен
+	monoRatio    = 0.8
+	bigramMaxIC  = 0.85
+	minChiLetter = 0.5 // нижняя граница ожидаемой частоты (%) для χ²
+)
+
+// language — параметры языка потока.
+type language struct {
+	title    string
+	alphabet string
+	freq     []float64
+	merged   rune // буква, отсутствующая в таблицах биграммных шифров
+}
+
+var languages = []language{
+	{"Латиница", classic.LatinAlphabet, classic.EnglishFreq, 'j'},
+	{"Кириллица", classic.CyrillicAlphabet, classic.RussianFreq, 'ъ'},
+}
+
+// bar рисует столбец гистограммы.
+func bar(value, maxValue int) string {
+	if maxValue == 0 {
+		return ""
+	}
+	return strings.Repeat("█", int(math.Round(float64(value)*barWidth/float64(maxValue))))
+}
+
+// printLetters печатает частоты букв по убыванию рядом с частотами языка.
+func printLetters(s *classic.Stream, lang language) {
+	counts := s.Counts()
+	n := len(s.Letters)
+	idx := classic.TopIndices(counts, 0)
+	fmt.Println("\n  Буквы (по убыванию):  число    %     язык %")
+	for _, i := range idx {
+		fmt.Printf("    %c  %6d  %6.2f  %6.2f  %s\n", s.Alphabet[i], counts[i],
+			100*float64(counts[i])/float64(n), lang.freq[i], bar(counts[i], counts[idx[0]]))
+	}
+	var missing []rune
+	for i, c := range counts {
+		if c == 0 {
+			missing = append(missing, s.Alphabet[i])
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("    не встречаются: %s\n", string(missing))
+	}
+}
+
+// printBigrams печатает самые частые биграммы.
+func printBigrams(s *classic.Stream) {
+	counts := s.Bigrams()
+	k := len(s.Alphabet)
+	idx := classic.TopIndices(counts, topBigrams)
+	if len(idx) == 0 {
+		return
+	}
+	fmt.Println("\n  Частые биграммы:")
+	for _, i := range idx {
+		fmt.Printf("    %c%c  %5d  %s\n", s.Alphabet[i/k], s.Alphabet[i%k], counts[i], bar(counts[i], counts[idx[0]]))
+	}
+}
+
+// leftMaximal сообщает, что повтор с вхождениями pos нельзя продлить влево:
+// перед вхождениями стоят разные буквы или одно из них в начале потока.
+func leftMaximal(s *classic.Stream, pos []int) bool {
+	for _, p := range pos {
+		if p == 0 || s.Letters[p-1] != s.Letters[pos[0]-1] {
+			return true
+		}
+	}
+	return false
+}
+
+// collectRepeats продлевает повтор длины n с вхождениями pos вправо, пока
+// следующие буквы совпадают; когда они расходятся, повтор записывается,
+// а группы из двух и более вхождений с одинаковой буквой продлеваются дальше.
+func collectRepeats(s *classic.Stream, pos []int, n int, out []classic.Repeat) []classic.Repeat {
+	for {
+		groups := make(map[int][]int)
+		var order []int
+		for _, p := range pos {
+			if p+n >= len(s.Letters) {
+				continue
+			}
+			l := s.Letters[p+n]
+			if groups[l] == nil {
+				order = append(order, l)
+			}
+			groups[l] = append(groups[l], p)
+		}
+		if len(order) == 1 && len(groups[order[0]]) == len(pos) {
+			n++
+			continue
+		}
+		if leftMaximal(s, pos) {
+			r := classic.Repeat{Gram: s.Gram(pos[0], n), Positions: pos}
+			for i := 1; i < len(pos); i++ {
+				r.Distances = append(r.Distances, pos[i]-pos[i-1])
+			}
+			out = append(out, r)
+		}
+		for _, l := range order {
+			if len(groups[l]) > 1 {
+				out = collectRepeats(s, groups[l], n+1, out)
+			}
+		}
+		return out
+	}
+}
+
+// maximalRepeats продлевает повторы триграмм до повторов наибольшей длины,
+// которые нельзя продлить ни влево, ни вправо; самые длинные идут первыми.
+// Повтор, короткое начало которого встречается чаще, записывается отдельно.
+func maximalRepeats(s *classic.Stream) []classic.Repeat {
+	var repeats []classic.Repeat
+	for _, r := range s.Repeats(3) {
+		repeats = collectRepeats(s, r.Positions, 3, repeats)
+	}
+	slices.SortStableFunc(repeats, func(a, b classic.Repeat) int { return len(b.Gram) - len(a.Gram) })
+	return repeats
+}
+
+// printRepeats печатает повторы n-грамм (n ≥ 3, сначала самые длинные)
+// и делители расстояний между ними — основу метода Касиски.
+func printRepeats(s *classic.Stream) {
+	repeats := maximalRepeats(s)
+	if len(repeats) == 0 {
+		fmt.Println("\n  Повторов n-грамм (n ≥ 3) нет.")
+		return
+	}
+
+	factors := make([]int, maxPeriod+1)
+	fmt.Println("\n  Повторы n-грамм: позиции и расстояния")
+	for i, r := range repeats {
+		if i < topRepeats {
+			fmt.Printf("    %-12s позиции %v, расстояния %v\n", r.Gram, r.Positions, r.Distances)
+		}
+		for _, d := range r.Distances {
+			for f := 2; f <= maxPeriod; f++ {
+				if d%f == 0 {
+					factors[f]++
+				}
+			}
+		}
+	}
+	if len(repeats) > topRepeats {
+		fmt.Printf("    … всего повторов: %d\n", len(repeats))
+	}
+
+	fmt.Print("  Делители расстояний (делитель: число):")
+	for f := 2; f <= maxPeriod; f++ {
+		if factors[f] > 0 {
+			fmt.Printf(" %d:%d", f, factors[f])
+		}
+	}
+	fmt.Println()
+}
+
+// guessFamily оценивает тип шифра по индексу совпадений и структуре биграмм.
+func guessFamily(s *classic.Stream, lang language) string {
+	n := len(s.Letters)
+	if n < minLetters {
+		return fmt.Sprintf("букв меньше %d — вывод ненадёжен", minLetters)
+	}
+	plain := classic.ExpectedIC(lang.freq)
+	random := 1 / float64(len(s.Alphabet))
+	ratio := (s.IC() - random) / (plain - random)
+
+	// Плейфейр и двойной квадрат дают чётное число букв, не используют
+	// объединённую букву, а Плейфейр ещё и не повторяет букву внутри биграммы.
+	doubles := 0
+	for i := 0; i+1 < n; i += 2 {
+		if s.Letters[i] == s.Letters[i+1] {
+			doubles++
+		}
+	}
+	mergedUsed := s.Counts()[classic.IndexOf(s.Alphabet, lang.merged)] > 0
+	switch {
+	case n%2 == 0 && !mergedUsed && doubles == 0 && ratio < bigramMaxIC:
+		return "биграммный шифр (Плейфейр): чётная длина, нет удвоенных букв в биграммах, нет «" + string(lang.merged) + "»"
+	case n%2 == 0 && !mergedUsed && ratio < monoRatio && ratio > 0.3:
+		return "вероятно биграммный шифр (двойной квадрат) или многоалфавитный с коротким ключом"
+	case ratio >= monoRatio:
+		if s.ChiSquared(lang.freq, minChiLetter)/float64(n) < 0.5 {
+			return "частоты совпадают с языком: открытый текст или перестановка"
+		}
+		return "моноалфавитная замена (индекс совпадений как у языка, частоты переставлены)"
+	default:
+		nf := float64(n)
+		period := nf * (plain - random) / ((nf-1)*s.IC() - nf*random + plain)
+		return fmt.Sprintf("многоалфавитный шифр (Виженер, гаммирование), оценка периода по Фридману ≈ %.1f", period)
+	}
+}
+
+// analyzeStream печатает полный отчёт по потоку одного алфавита.
+func analyzeStream(s *classic.Stream, lang language) {
+	n := len(s.Letters)
+	fmt.Printf("\n=== %s: %d букв ===\n", lang.title, n)
+	if n < 2 {
+		fmt.Println("  Слишком мало букв для анализа.")
+		return
+	}
+	fmt.Printf("  Индекс совпадений: %.4f (язык %.4f, случайный текст %.4f)\n",
+		s.IC(), classic.ExpectedIC(lang.freq), 1/float64(len(s.Alphabet)))
+	fmt.Printf("  Энтропия:          %.3f бит/букву (максимум %.3f)\n",
+		s.Entropy(), math.Log2(float64(len(s.Alphabet))))
+	fmt.Println("  Предположение:    ", guessFamily(s, lang))
+	printLetters(s, lang)
+	printBigrams(s)
+	printRepeats(s)
+}
+
+// analyze разбирает текст на потоки и анализирует каждый непустой.
+func analyze(text string) {
+	lat, cyr := classic.Streams(classic.Tokenize(text))
+	if len(lat) == 0 && len(cyr) == 0 {
+		fmt.Println("Ошибка: в тексте нет букв")
+		return
+	}
+	for i, letters := range [][]rune{lat, cyr} {
+		if len(letters) > 0 {
+			analyzeStream(classic.NewStream(letters, languages[i].alphabet), languages[i])
+		}
+	}
+	fmt.Println()
+}
+
+func main() {
+	fmt.Println()
+
+	for {
+		fmt.Println("Анализ шифртекста")
+		fmt.Println("  1 — Ввести текст")
+		fmt.Println("  2 — Прочитать из файла")
+		fmt.Println("  0 — Выход")
+		choice := strings.TrimSpace(classic.ReadLine(": "))
+
+		switch choice {
+		case "1":
+			analyze(classic.ReadLine("Введите текст: "))
+
+		case "2":
+			path := strings.TrimSpace(classic.ReadLine("Файл: "))
+			data, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			analyze(string(data))
+
+		case "0":
+			fmt.Println("Выход.")
+			return
+
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"classic"
+)
+
+const sample = "It was the best of times, it was the worst of times, it was the age of wisdom, " +
+	"it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity, " +
+	"it was the season of Light, it was the season of Darkness, it was the spring of hope, " +
+	"it was the winter of despair, we had everything before us, we had nothing before us, " +
+	"we were all going direct to Heaven, we were all going direct the other way."
+
+// encrypt шифрует sample шифром Виженера с ключом key.
+func encrypt(t *testing.T, key classic.VigenereKey) *classic.Stream {
+	t.Helper()
+	v, err := classic.NewVigenere(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := v.Encrypt(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return classic.NewStream([]rune(ct), classic.LatinAlphabet)
+}
+
+// TestGuessFamily проверяет предположение о типе шифра для открытого текста,
+// шифра Цезаря и шифра Виженера.
+func TestGuessFamily(t *testing.T) {
+	tests := []struct {
+		name string
+		key  classic.VigenereKey
+		want string
+	}{
+		{"открытый текст", "a", "открытый текст"},
+		{"Цезарь", "d", "моноалфавитная"},
+		{"Виженер", "lighthouse", "многоалфавитный"},
+	}
+	for _, tt := range tests {
+		got := guessFamily(encrypt(t, tt.key), languages[0])
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: %q, ожидается «%s»", tt.name, got, tt.want)
+		}
+	}
+
+	short := classic.NewStream([]rune("attack at dawn"), classic.LatinAlphabet)
+	if got := guessFamily(short, languages[0]); !strings.Contains(got, "ненадёжен") {
+		t.Errorf("короткий текст: %q, ожидается предупреждение", got)
+	}
+}
+
+// TestMaximalRepeats проверяет склейку повторов триграмм в повторы
+// наибольшей длины и расстояния между ними.
+func TestMaximalRepeats(t *testing.T) {
+	s := classic.NewStream([]rune("abcdef xx abcdef yy abc"), classic.LatinAlphabet)
+	got := maximalRepeats(s)
+	want := []classic.Repeat{
+		{Gram: "abcdef", Positions: []int{0, 8}, Distances: []int{8}},
+		{Gram: "abc", Positions: []int{0, 8, 16}, Distances: []int{8, 8}},
+	}
+	if !slices.EqualFunc(got, want, func(a, b classic.Repeat) bool {
+		return a.Gram == b.Gram && slices.Equal(a.Positions, b.Positions) && slices.Equal(a.Distances, b.Distances)
+	}) {
+		t.Errorf("повторы %+v, ожидается %+v", got, want)
+	}
+}
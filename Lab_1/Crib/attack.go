@@ -0,0 +1,519 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"classic"
+)
+
+// Атака на повторно использованную гамму. Если два сообщения зашифрованы
+// одной гаммой, то для известного (угаданного) куска P одного из них гамма
+// на этих позициях равна K = C − P, а соответствующий кусок любого другого
+// сообщения — C' − K. Слово-«шпаргалка» (crib) прикладывается ко всем
+// позициям всех шифртекстов, и положения, при которых остальные сообщения
+// превращаются в правдоподобный текст, открывают участок гаммы.
+
+// kind — способ наложения гаммы.
+type kind int
+
+const (
+	kindBytes   kind = iota // байты: C = P XOR K (Вернам, одноразовый блокнот)
+	kindLetters             // буквы: C = (P + K) mod N (gammaCipher), не-буквы гамму не расходуют
+)
+
+func (k kind) String() string {
+	if k == kindLetters {
+		return "буквы, сложение по модулю алфавита (гамма-шифр)"
+	}
+	return "байты, XOR (шифр Вернама)"
+}
+
+// sym — символ: байт (a = -1) или позиция буквы в алфавите a.
+type sym struct {
+	v, a int
+}
+
+// message — шифртекст. Символ i наложен на позицию start+i гаммы.
+type message struct {
+	name  string
+	syms  []sym
+	start int
+	text  []rune // буквенный режим: исходный текст, чтобы вывести не-буквы
+	at    []int  // буквенный режим: позиция символа в text
+}
+
+// keyCell — восстановленный символ гаммы: сдвиг по модулю алфавита a
+// (для байтов — байт гаммы, a = -1).
+type keyCell struct {
+	shift, a int
+	known    bool
+}
+
+// attack — шифртексты и восстановленная часть общей гаммы.
+type attack struct {
+	kind      kind
+	alphabets classic.Alphabets
+	models    []*trigramModel // модели языка по алфавитам (nil — нет таблицы квадграмм)
+	msgs      []*message
+	key       []keyCell
+}
+
+func newAttack(k kind) *attack {
+	a := &attack{kind: k, alphabets: classic.DefaultAlphabets}
+	for _, alpha := range a.alphabets {
+		a.models = append(a.models, newLanguageModel(alpha))
+	}
+	return a
+}
+
+// otpMagic — заголовок шифртекста одноразового блокнота (программа XOR):
+// за сигнатурой следует смещение в блокноте, 8 байт big-endian.
+const otpMagic = "OTP1"
+
+// maxOTPOffset — наибольшее смещение из заголовка. Гамма хранится с нулевой
+// позиции, поэтому смещение определяет размер выделяемой памяти; 16 МиБ
+// в 16 раз больше блокнота, который программа XOR создаёт по умолчанию.
+const maxOTPOffset = 1 << 24
+
+// addBytes добавляет байтовый шифртекст. Заголовок одноразового блокнота
+// снимается, а его смещение определяет положение сообщения в гамме.
+func (a *attack) addBytes(name string, data []byte) error {
+	m := &message{name: name}
+	if len(data) >= len(otpMagic)+8 && string(data[:len(otpMagic)]) == otpMagic {
+		off := binary.BigEndian.Uint64(data[len(otpMagic):])
+		if off > maxOTPOffset {
+			return fmt.Errorf("%s: смещение в блокноте %d больше допустимого (%d)", name, off, maxOTPOffset)
+		}
+		m.start = int(off)
+		data = data[len(otpMagic)+8:]
+	}
+	for _, b := range data {
+		m.syms = append(m.syms, sym{v: int(b), a: -1})
+	}
+	a.add(m)
+	return nil
+}
+
+// addText добавляет шифртекст гамма-шифра: символами становятся буквы
+// алфавитов, остальное сохраняется только для вывода.
+func (a *attack) addText(name, text string) {
+	m := &message{name: name, text: []rune(text)}
+	for i, r := range m.text {
+		if s, ok := a.letter(r); ok {
+			m.syms = append(m.syms, s)
+			m.at = append(m.at, i)
+		}
+	}
+	a.add(m)
+}
+
+func (a *attack) add(m *message) {
+	a.msgs = append(a.msgs, m)
+	if n := m.start + len(m.syms); n > len(a.key) {
+		a.key = append(a.key, make([]keyCell, n-len(a.key))...)
+	}
+}
+
+// letter возвращает символ буквы r (алфавит и позицию).
+func (a *attack) letter(r rune) (sym, bool) {
+	alpha, pos := a.alphabets.Lookup(r)
+	if alpha == nil {
+		return sym{}, false
+	}
+	for i := range a.alphabets {
+		if &a.alphabets[i] == alpha {
+			return sym{v: pos, a: i}, true
+		}
+	}
+	return sym{}, false
+}
+
+// mod возвращает модуль символа: 256 для байта или размер его алфавита.
+func (a *attack) mod(s sym) int {
+	if s.a < 0 {
+		return 256
+	}
+	return len(a.alphabets[s.a].Letters)
+}
+
+// encodeCrib переводит слово в символы: байты UTF-8 или буквы алфавитов
+// (не-буквы гамма-шифр не шифрует, поэтому они отбрасываются).
+func (a *attack) encodeCrib(crib string) []sym {
+	var out []sym
+	if a.kind == kindBytes {
+		for _, b := range []byte(crib) {
+			out = append(out, sym{v: int(b), a: -1})
+		}
+		return out
+	}
+	for _, r := range crib {
+		if s, ok := a.letter(unicode.ToLower(r)); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// keyFrom возвращает символ гаммы, переводящий p в c (алфавиты должны совпадать).
+func (a *attack) keyFrom(c, p sym) (keyCell, bool) {
+	if c.a != p.a {
+		return keyCell{}, false
+	}
+	if a.kind == kindBytes {
+		return keyCell{shift: c.v ^ p.v, a: -1, known: true}, true
+	}
+	n := a.mod(c)
+	return keyCell{shift: ((c.v-p.v)%n + n) % n, a: c.a, known: true}, true
+}
+
+// plain снимает гамму k с символа c. Сдвиг, найденный в алфавите другого
+// размера, к букве неприменим.
+func (a *attack) plain(c sym, k keyCell) (sym, bool) {
+	if !k.known {
+		return sym{}, false
+	}
+	if a.kind == kindBytes {
+		return sym{v: c.v ^ k.shift, a: -1}, true
+	}
+	n := a.mod(c)
+	if k.a != c.a && n != len(a.alphabets[k.a].Letters) {
+		return sym{}, false
+	}
+	return sym{v: ((c.v-k.shift)%n + n) % n, a: c.a}, true
+}
+
+// Оценка правдоподобия. Байты оцениваются как текст UTF-8: средний
+// десятичный логарифм вероятности символа по частотам букв языка
+// (classic.EnglishFreq, classic.RussianFreq), пробела и пунктуации. Буквы
+// гамма-шифра — по триграммам языка, в шкале от 0 (случайные буквы) до 1
+// (текст языка).
+const (
+	logUnlikely = -4.0 // печатный символ вне модели
+	logInvalid  = -7.0 // управляющий символ или неверный UTF-8
+)
+
+// runeLog оценивает символ текста: строчные буквы — по частотам языка,
+// прописные реже, пробел и пунктуация — по типичной доле в тексте.
+func runeLog(r rune) float64 {
+	lower := unicode.ToLower(r)
+	weight := 0.8
+	if lower != r {
+		weight = 0.04
+	}
+	switch {
+	case r == ' ':
+		return math.Log10(0.16)
+	case strings.ContainsRune(".,;:!?'\"-()\n", r):
+		return math.Log10(0.004)
+	case r >= '0' && r <= '9':
+		return math.Log10(0.002)
+	}
+	for i, alpha := range []string{classic.LatinAlphabet, classic.CyrillicAlphabet} {
+		if pos := classic.IndexOf([]rune(alpha), lower); pos >= 0 {
+			freq := [][]float64{classic.EnglishFreq, classic.RussianFreq}[i]
+			return math.Log10(weight * max(freq[pos], 0.04) / 100)
+		}
+	}
+	if unicode.IsPrint(r) {
+		return logUnlikely
+	}
+	return logInvalid
+}
+
+// fragmentLog возвращает сумму оценок символов куска открытого текста и
+// их число. Байты декодируются как UTF-8; обрезанный многобайтовый символ
+// на краю куска не оценивается.
+func (a *attack) fragmentLog(frag []sym) (float64, int) {
+	var sum float64
+	var count int
+	if a.kind == kindLetters {
+		// участки букв одного алфавита оцениваются моделью этого алфавита
+		for len(frag) > 0 {
+			n := 1
+			for n < len(frag) && frag[n].a == frag[0].a {
+				n++
+			}
+			if m := a.models[frag[0].a]; m != nil {
+				run := make([]int, n)
+				for i, s := range frag[:n] {
+					run[i] = s.v
+				}
+				sum += (m.score(run) - m.random*float64(n)) / m.spread
+			}
+			count += n
+			frag = frag[n:]
+		}
+		return sum, count
+	}
+	buf := make([]byte, len(frag))
+	for i, s := range frag {
+		buf[i] = byte(s.v)
+	}
+	for i := 0; i < len(buf); {
+		r, size := utf8.DecodeRune(buf[i:])
+		switch {
+		case r != utf8.RuneError:
+			sum += runeLog(r)
+			count++
+		case i == 0 && buf[i] >= 0x80 && buf[i] < 0xC0:
+			// продолжение символа, начатого до куска
+		case !utf8.FullRune(buf[i:]):
+			// начало символа, который продолжается после куска
+		default:
+			sum += logInvalid
+			count++
+		}
+		i += size
+	}
+	return sum, count
+}
+
+// minScore — порог средней оценки символа, выше которого текст считается
+// правдоподобным: середина между текстом языка и случайными символами.
+func (a *attack) minScore() float64 {
+	if a.kind == kindBytes {
+		return -1.35 // английский текст ≈ −1.2, случайные байты ≈ −5
+	}
+	return 0.7
+}
+
+// candidate — положение шпаргалки: сообщение, позиция символа в нём,
+// участок гаммы и оценка открывшихся кусков остальных сообщений.
+type candidate struct {
+	msg, pos int
+	crib     []sym
+	key      []keyCell
+	score    float64
+	frags    []string // куски остальных сообщений
+}
+
+// place вычисляет гамму для шпаргалки crib в сообщении mi с позиции pos.
+// Несовпадение алфавитов или противоречие уже известной гамме — отказ.
+func (a *attack) place(mi, pos int, crib []sym) ([]keyCell, error) {
+	m := a.msgs[mi]
+	if pos < 0 || pos+len(crib) > len(m.syms) {
+		return nil, fmt.Errorf("шпаргалка не помещается в сообщение %d с позиции %d", mi+1, pos)
+	}
+	key := make([]keyCell, len(crib))
+	for t, p := range crib {
+		k, ok := a.keyFrom(m.syms[pos+t], p)
+		if !ok {
+			return nil, fmt.Errorf("позиция %d: буква шпаргалки из другого алфавита", pos+t)
+		}
+		if old := a.key[m.start+pos+t]; old.known && a.mod(sym{a: old.a}) == a.mod(sym{a: k.a}) && old.shift != k.shift {
+			return nil, fmt.Errorf("позиция %d: противоречит уже восстановленной гамме", pos+t)
+		}
+		key[t] = k
+	}
+	return key, nil
+}
+
+// evaluate снимает участок гаммы key (с позиции gamma) с остальных
+// сообщений и оценивает результат. Сообщения, не перекрывающие участок,
+// не учитываются; если остальные сообщения вместе перекрывают меньше
+// символов, чем в участке, оценка — −∞: по одной-двум буквам на краю
+// сообщения правдоподобие не определить.
+func (a *attack) evaluate(mi, gamma int, key []keyCell) (float64, []string) {
+	var sum float64
+	var count, overlap int
+	var frags []string
+	for j, m := range a.msgs {
+		if j == mi {
+			continue
+		}
+		var frag []sym
+		for t, k := range key {
+			i := gamma + t - m.start
+			if i < 0 || i >= len(m.syms) {
+				continue
+			}
+			overlap++
+			if p, ok := a.plain(m.syms[i], k); ok {
+				frag = append(frag, p)
+			}
+		}
+		s, n := a.fragmentLog(frag)
+		sum, count = sum+s, count+n
+		frags = append(frags, a.format(frag))
+	}
+	if count == 0 || overlap < len(key) {
+		return math.Inf(-1), frags
+	}
+	return sum / float64(count), frags
+}
+
+// drag прикладывает шпаргалку ко всем позициям всех сообщений и возвращает
+// положения по убыванию оценки.
+func (a *attack) drag(crib string) []candidate {
+	syms := a.encodeCrib(crib)
+	if len(syms) == 0 {
+		return nil
+	}
+	var out []candidate
+	for mi, m := range a.msgs {
+		for pos := 0; pos+len(syms) <= len(m.syms); pos++ {
+			key, err := a.place(mi, pos, syms)
+			if err != nil || a.known(m.start+pos, len(key)) {
+				continue
+			}
+			score, frags := a.evaluate(mi, m.start+pos, key)
+			if math.IsInf(score, -1) {
+				continue
+			}
+			out = append(out, candidate{msg: mi, pos: pos, crib: syms, key: key, score: score, frags: frags})
+		}
+	}
+	slices.SortStableFunc(out, func(x, y candidate) int {
+		switch {
+		case x.score > y.score:
+			return -1
+		case x.score < y.score:
+			return 1
+		}
+		return 0
+	})
+	return out
+}
+
+// known сообщает, восстановлена ли вся гамма на участке [from, from+n).
+func (a *attack) known(from, n int) bool {
+	for _, k := range a.key[from : from+n] {
+		if !k.known {
+			return false
+		}
+	}
+	return true
+}
+
+// accept записывает участок гаммы положения c.
+func (a *attack) accept(c candidate) {
+	copy(a.key[a.msgs[c.msg].start+c.pos:], c.key)
+}
+
+// minAutoCrib возвращает наименьшую длину шпаргалки (в символах) для
+// автоматического подбора: более короткие правдоподобно ложатся почти куда
+// угодно. Буквам без пробелов нужен кусок длиннее.
+func (a *attack) minAutoCrib() int {
+	if a.kind == kindLetters {
+		return 5
+	}
+	return 4
+}
+
+// auto жадно принимает лучшие положения шпаргалок cribs, пока они
+// правдоподобнее порога и не противоречат найденной гамме. Возвращает
+// принятые положения по порядку.
+func (a *attack) auto(cribs []string) []candidate {
+	var accepted []candidate
+	for {
+		var best *candidate
+		for _, crib := range cribs {
+			if len(a.encodeCrib(crib)) < a.minAutoCrib() {
+				continue
+			}
+			cands := a.drag(crib)
+			if len(cands) > 0 && (best == nil || cands[0].score > best.score) {
+				best = &cands[0]
+			}
+		}
+		if best == nil || best.score < a.minScore() {
+			return accepted
+		}
+		a.accept(*best)
+		accepted = append(accepted, *best)
+	}
+}
+
+// recovered возвращает долю восстановленной гаммы.
+func (a *attack) recovered() (int, int) {
+	n := 0
+	for _, k := range a.key {
+		if k.known {
+			n++
+		}
+	}
+	return n, len(a.key)
+}
+
+// format записывает символы для вывода: буквы алфавитов или байты как
+// текст UTF-8 (непечатные — «·»).
+func (a *attack) format(syms []sym) string {
+	if a.kind == kindLetters {
+		var sb strings.Builder
+		for _, s := range syms {
+			sb.WriteRune(a.alphabets[s.a].Letters[s.v])
+		}
+		return sb.String()
+	}
+	buf := make([]byte, len(syms))
+	for i, s := range syms {
+		buf[i] = byte(s.v)
+	}
+	return printable(buf)
+}
+
+// printable заменяет непечатные символы и неверный UTF-8 точкой «·».
+func printable(buf []byte) string {
+	var sb strings.Builder
+	for len(buf) > 0 {
+		r, size := utf8.DecodeRune(buf)
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '·'
+		}
+		sb.WriteRune(r)
+		buf = buf[size:]
+	}
+	return sb.String()
+}
+
+// plaintext восстанавливает сообщение по известной гамме: неизвестные
+// символы — «_», в буквенном режиме не-буквы и регистр берутся из шифртекста.
+func (a *attack) plaintext(m *message) string {
+	if a.kind == kindBytes {
+		buf := make([]byte, len(m.syms))
+		for i, c := range m.syms {
+			buf[i] = '_'
+			if p, ok := a.plain(c, a.key[m.start+i]); ok {
+				buf[i] = byte(p.v)
+			}
+		}
+		return printable(buf)
+	}
+	out := slices.Clone(m.text)
+	for i, c := range m.syms {
+		r := '_'
+		if p, ok := a.plain(c, a.key[m.start+i]); ok {
+			r = a.alphabets[p.a].Letters[p.v]
+			if unicode.IsUpper(m.text[m.at[i]]) {
+				r = unicode.ToUpper(r)
+			}
+		}
+		out[m.at[i]] = r
+	}
+	return string(out)
+}
+
+// keyString записывает восстановленную гамму: байты в hex или буквы
+// (сдвиг s — s-я буква алфавита), неизвестное — «_».
+func (a *attack) keyString() string {
+	var sb strings.Builder
+	for _, k := range a.key {
+		switch {
+		case !k.known && a.kind == kindBytes:
+			sb.WriteString("__")
+		case !k.known:
+			sb.WriteRune('_')
+		case a.kind == kindBytes:
+			fmt.Fprintf(&sb, "%02x", k.shift)
+		default:
+			sb.WriteRune(a.alphabets[k.a].Letters[k.shift])
+		}
+	}
+	return sb.String()
+}
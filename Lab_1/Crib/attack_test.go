@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"classic"
+)
+
+// otpMessage собирает шифртекст с заголовком одноразового блокнота.
+func otpMessage(off uint64, body []byte) []byte {
+	data := make([]byte, len(otpMagic)+8, len(otpMagic)+8+len(body))
+	copy(data, otpMagic)
+	binary.BigEndian.PutUint64(data[len(otpMagic):], off)
+	return append(data, body...)
+}
+
+// TestAddBytesOffset проверяет, что смещение из заголовка задаёт положение
+// сообщения в гамме, а слишком большое смещение отклоняется.
+func TestAddBytesOffset(t *testing.T) {
+	a := newAttack(kindBytes)
+	if err := a.addBytes("1", otpMessage(100, []byte("abc"))); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.msgs[0].start; got != 100 {
+		t.Errorf("начало сообщения %d, ожидается 100", got)
+	}
+	if got := len(a.key); got != 103 {
+		t.Errorf("длина гаммы %d, ожидается 103", got)
+	}
+
+	for _, off := range []uint64{maxOTPOffset + 1, 1 << 63, ^uint64(0)} {
+		if err := a.addBytes("2", otpMessage(off, []byte("abc"))); err == nil {
+			t.Errorf("смещение %d: ожидается ошибка", off)
+		}
+	}
+	if len(a.msgs) != 1 || len(a.key) != 103 {
+		t.Errorf("отклонённое сообщение изменило атаку: %d сообщений, гамма %d", len(a.msgs), len(a.key))
+	}
+}
+
+// Два открытых текста, зашифрованные одной гаммой.
+const (
+	textKeeper = "The old lighthouse keeper climbed the stairs every evening to light the great lamp, and every morning he climbed them again to put it out. He had done this for forty years, and he knew every step by the sound it made under his boots."
+	textStorm  = "When the storm came in from the west, the fishing boats hurried back to the harbour because the sky had turned the colour of slate and the wind was rising faster than anyone in the village could remember."
+)
+
+// byteAttack шифрует оба текста одной случайной байтовой гаммой.
+func byteAttack(t *testing.T) (*attack, []byte) {
+	t.Helper()
+	key := make([]byte, len(textKeeper))
+	rand.New(rand.NewSource(1)).Read(key)
+	a := newAttack(kindBytes)
+	for i, text := range []string{textKeeper, textStorm} {
+		data := []byte(text)
+		for j := range data {
+			data[j] ^= key[j]
+		}
+		if err := a.addBytes(strconv.Itoa(i+1), data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return a, key
+}
+
+// letterAttack шифрует оба текста одной случайной буквенной гаммой.
+func letterAttack(t *testing.T) (*attack, []int) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	var sb strings.Builder
+	key := make([]int, len(textKeeper))
+	for i := range key {
+		key[i] = rng.Intn(26)
+		sb.WriteByte(byte('a' + key[i]))
+	}
+	g, err := classic.NewGamma(classic.GammaKey(sb.String()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newAttack(kindLetters)
+	for i, text := range []string{textKeeper, textStorm} {
+		ct, err := g.Encrypt(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.addText(strconv.Itoa(i+1), ct)
+	}
+	return a, key
+}
+
+// letterIndex возвращает номер буквы, с которой crib входит в text.
+func letterIndex(text, crib string) int {
+	n := 0
+	for _, r := range text[:strings.Index(text, crib)] {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// TestDragBytes проверяет, что верное положение шпаргалки идёт первым.
+// При двух сообщениях XOR симметричен: та же шпаргалка в другом сообщении
+// на той же позиции даёт ту же оценку, поэтому сверяется только позиция.
+func TestDragBytes(t *testing.T) {
+	a, _ := byteAttack(t)
+	for _, crib := range []string{" lighthouse keeper ", " every evening ", " climbed the stairs "} {
+		c := a.drag(crib)
+		if len(c) == 0 {
+			t.Fatalf("%q: нет кандидатов", crib)
+		}
+		if want := strings.Index(textKeeper, crib); c[0].pos != want {
+			t.Errorf("%q: лучшая позиция %d, ожидается %d", crib, c[0].pos, want)
+		}
+	}
+}
+
+// TestDragLetters проверяет, что верное положение шпаргалки идёт первым.
+func TestDragLetters(t *testing.T) {
+	a, _ := letterAttack(t)
+	tests := []struct {
+		crib string
+		msg  int
+		text string
+	}{
+		{"lighthouse keeper", 0, textKeeper},
+		{"fishing boats", 1, textStorm},
+	}
+	for _, tt := range tests {
+		c := a.drag(tt.crib)
+		if len(c) == 0 {
+			t.Fatalf("%q: нет кандидатов", tt.crib)
+		}
+		want := letterIndex(tt.text, tt.crib)
+		if c[0].msg != tt.msg || c[0].pos != want {
+			t.Errorf("%q: лучшее положение %d:%d, ожидается %d:%d", tt.crib, c[0].msg, c[0].pos, tt.msg, want)
+		}
+	}
+}
+
+// TestAutoBytes проверяет, что автоматический подбор раскрывает гамму
+// под шпаргалками и не принимает ни одного неверного байта.
+func TestAutoBytes(t *testing.T) {
+	a, key := byteAttack(t)
+	cribs := []string{" lighthouse keeper ", " every evening ", " climbed the stairs "}
+	a.auto(cribs)
+	for i, k := range a.key {
+		if k.known && byte(k.shift) != key[i] {
+			t.Errorf("байт гаммы %d: %#x, ожидается %#x", i, k.shift, key[i])
+		}
+	}
+	for _, crib := range cribs {
+		if pos := strings.Index(textKeeper, crib); !a.known(pos, len(crib)) {
+			t.Errorf("%q: гамма на позициях %d–%d не раскрыта", crib, pos, pos+len(crib)-1)
+		}
+	}
+}
+
+// TestAutoLetters проверяет, что автоматический подбор раскрывает гамму
+// под шпаргалками и не принимает ни одной неверной буквы.
+func TestAutoLetters(t *testing.T) {
+	a, key := letterAttack(t)
+	tests := []struct {
+		crib string
+		text string
+	}{
+		{"lighthouse keeper", textKeeper},
+		{"fishing boats", textStorm},
+	}
+	var cribs []string
+	for _, tt := range tests {
+		cribs = append(cribs, tt.crib)
+	}
+	a.auto(cribs)
+	for i, k := range a.key {
+		if k.known && k.shift != key[i] {
+			t.Errorf("буква гаммы %d: сдвиг %d, ожидается %d", i, k.shift, key[i])
+		}
+	}
+	for _, tt := range tests {
+		pos, n := letterIndex(tt.text, tt.crib), len(a.encodeCrib(tt.crib))
+		if !a.known(pos, n) {
+			t.Errorf("%q: гамма на позициях %d–%d не раскрыта", tt.crib, pos, pos+n-1)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"classic"
+)
+
+// Подбор «шпаргалок» (crib dragging) для шифртекстов, зашифрованных одной и
+// той же гаммой: гамма-шифром (буквы) или XOR (байты).
+
+// defaultCribs — частые слова и сочетания для автоматического подбора:
+// для байтов — с пробелами, для букв (пробелы гамма-шифр не сохраняет) —
+// слова и сочетания подлиннее.
+var defaultCribs = map[kind][]string{
+	kindBytes: {
+		" the ", " and ", " that ", " with ", " this ", " of the ", "tion", " is ", " to ",
+		" что ", " это ", " как ", " не ", " на ", " в ", "ение", " для ",
+	},
+	kindLetters: {
+		"ofthe", "which", "there", "would", "their", "about", "tion",
+		"которые", "который", "потомучто", "через", "когда", "только", "может", "ение",
+	},
+}
+
+// loadCiphertext читает шифртекст: «@файл» или сам текст (в байтовом режиме —
+// hex). Файл в байтовом режиме читается как есть, если не задан -hex.
+func loadCiphertext(a *attack, name, spec string, hexFiles bool) error {
+	data := []byte(spec)
+	fromFile := strings.HasPrefix(spec, "@")
+	if fromFile {
+		var err error
+		if data, err = os.ReadFile(spec[1:]); err != nil {
+			return fmt.Errorf("не удалось прочитать %q: %w", spec[1:], err)
+		}
+	}
+	if a.kind == kindLetters {
+		a.addText(name, string(data))
+		return nil
+	}
+	if !fromFile || hexFiles {
+		decoded, err := hex.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if err != nil {
+			return fmt.Errorf("%s: неверная hex-строка: %w", name, err)
+		}
+		data = decoded
+	}
+	return a.addBytes(name, data)
+}
+
+// printState выводит восстановленные тексты и гамму.
+func printState(a *attack) {
+	known, total := a.recovered()
+	fmt.Printf("\nВосстановлено символов гаммы: %d из %d\n", known, total)
+	for i, m := range a.msgs {
+		fmt.Printf("  %d (%s, с позиции гаммы %d): %s\n", i+1, m.name, m.start, a.plaintext(m))
+	}
+	fmt.Println("  Гамма:", a.keyString())
+	fmt.Println()
+}
+
+// printCandidates выводит первые top положений шпаргалки.
+func printCandidates(a *attack, cands []candidate, top int) {
+	for n, c := range cands[:min(top, len(cands))] {
+		mark := " "
+		if c.score >= a.minScore() {
+			mark = "*" // правдоподобнее порога автоматического режима
+		}
+		fmt.Printf("  %2d%s сообщение %d, позиция %4d, оценка %6.2f:", n+1, mark, c.msg+1, c.pos, c.score)
+		for _, f := range c.frags {
+			fmt.Printf(" «%s»", f)
+		}
+		fmt.Println()
+	}
+}
+
+// runDrag — диалог: шпаргалка прикладывается ко всем позициям, выбранное
+// положение записывается в гамму.
+func runDrag(a *attack, top int) {
+	crib := classic.ReadLine("Шпаргалка: ")
+	cands := a.drag(crib)
+	if len(cands) == 0 {
+		fmt.Println("Шпаргалка никуда не помещается (или гамма на всех участках уже известна).")
+		return
+	}
+	fmt.Println("\nЛучшие положения (* — выше порога):")
+	printCandidates(a, cands, top)
+	line := strings.TrimSpace(classic.ReadLine("Принять положение номер (пусто — ни одно): "))
+	if line == "" {
+		fmt.Println()
+		return
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > min(top, len(cands)) {
+		fmt.Println("Неверный номер.")
+		return
+	}
+	a.accept(cands[n-1])
+	printState(a)
+}
+
+// runPlace — диалог: известный (угаданный) текст ставится в сообщение с
+// указанной позиции. Так продолжаются найденные слова.
+func runPlace(a *attack) {
+	mi, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine("Номер сообщения: ")))
+	if err != nil || mi < 1 || mi > len(a.msgs) {
+		fmt.Println("Ошибка:", fmt.Errorf("нет сообщения с таким номером"))
+		return
+	}
+	pos, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine("Позиция символа: ")))
+	if err != nil {
+		fmt.Println("Ошибка:", fmt.Errorf("позиция должна быть числом"))
+		return
+	}
+	text := classic.ReadLine("Текст: ")
+	key, err := a.place(mi-1, pos, a.encodeCrib(text))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	a.accept(candidate{msg: mi - 1, pos: pos, key: key})
+	printState(a)
+}
+
+// runAuto применяет шпаргалки автоматически и сообщает принятые положения.
+func runAuto(a *attack, cribs []string) {
+	accepted := a.auto(cribs)
+	fmt.Printf("\nПринято положений: %d\n", len(accepted))
+	for _, c := range accepted {
+		fmt.Printf("  «%s» в сообщении %d с позиции %d (оценка %.2f)\n", a.format(c.crib), c.msg+1, c.pos, c.score)
+	}
+	printState(a)
+}
+
+// readCribs запрашивает список шпаргалок через «|» (пусто — встроенный словарь).
+func readCribs(def []string) []string {
+	line := classic.ReadLine("Шпаргалки через | (пусто — частые слова): ")
+	if strings.TrimSpace(line) == "" {
+		return def
+	}
+	return strings.Split(line, "|")
+}
+
+// readMessages — диалог ввода режима и шифртекстов.
+func readMessages(hexFiles bool) (*attack, error) {
+	fmt.Println("Гамма наложена на:")
+	fmt.Println("  1 — байты (XOR, одноразовый блокнот)")
+	fmt.Println("  2 — буквы (гамма-шифр)")
+	a := newAttack(kindBytes)
+	switch strings.TrimSpace(classic.ReadLine(": ")) {
+	case "1":
+	case "2":
+		a.kind = kindLetters
+	default:
+		return nil, fmt.Errorf("неверный выбор режима")
+	}
+	prompt := "hex или @файл"
+	if a.kind == kindLetters {
+		prompt = "текст или @файл"
+	}
+	for {
+		spec := strings.TrimSpace(classic.ReadLine(fmt.Sprintf("Шифртекст %d (%s, пусто — закончить): ", len(a.msgs)+1, prompt)))
+		if spec == "" {
+			break
+		}
+		if err := loadCiphertext(a, fmt.Sprintf("шифртекст %d", len(a.msgs)+1), spec, hexFiles); err != nil {
+			fmt.Println("Ошибка:", err)
+		}
+	}
+	if len(a.msgs) < 2 {
+		return nil, fmt.Errorf("нужно не меньше двух шифртекстов")
+	}
+	return a, nil
+}
+
+func main() {
+	mode := flag.String("mode", "bytes", "наложение гаммы: bytes (XOR) или letters (гамма-шифр)")
+	hexFiles := flag.Bool("hex", false, "файлы шифртекстов байтового режима записаны в hex")
+	cribList := flag.String("cribs", "", "шпаргалки через | для автоматического подбора (по умолчанию — частые слова)")
+	top := flag.Int("top", 10, "сколько лучших положений шпаргалки показывать")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Использование: crib [флаги] шифртекст1 шифртекст2 ... — автоматический подбор;")
+		fmt.Fprintln(flag.CommandLine.Output(), "без файлов — диалог.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var cribs []string
+	if *cribList != "" {
+		cribs = strings.Split(*cribList, "|")
+	}
+
+	if flag.NArg() > 0 {
+		a := newAttack(kindBytes)
+		var err error
+		switch *mode {
+		case "bytes":
+		case "letters":
+			a.kind = kindLetters
+		default:
+			err = fmt.Errorf("неизвестный режим %q", *mode)
+		}
+		for _, path := range flag.Args() {
+			if err == nil {
+				err = loadCiphertext(a, path, "@"+path, *hexFiles)
+			}
+		}
+		if err == nil && len(a.msgs) < 2 {
+			err = fmt.Errorf("нужно не меньше двух шифртекстов")
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
+		}
+		if cribs == nil {
+			cribs = defaultCribs[a.kind]
+		}
+		runAuto(a, cribs)
+		return
+	}
+
+	fmt.Println()
+	a, err := readMessages(*hexFiles)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		os.Exit(1)
+	}
+	printState(a)
+	if cribs == nil {
+		cribs = defaultCribs[a.kind]
+	}
+
+	for {
+		fmt.Println("Режим:", a.kind)
+		fmt.Println("1 — Приложить шпаргалку ко всем позициям")
+		fmt.Println("2 — Подставить известный текст в сообщение")
+		fmt.Println("3 — Автоматический подбор")
+		fmt.Println("4 — Показать тексты и гамму")
+		fmt.Println("5 — Забыть восстановленную гамму")
+		fmt.Println("0 — Выход")
+		choice := strings.TrimSpace(classic.ReadLine(": "))
+
+		switch choice {
+		case "1":
+			runDrag(a, *top)
+
+		case "2":
+			runPlace(a)
+
+		case "3":
+			runAuto(a, readCribs(cribs))
+
+		case "4":
+			printState(a)
+
+		case "5":
+			clear(a.key)
+			fmt.Println()
+
+		case "0":
+			fmt.Println("Выход.")
+			return
+
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
@@ -0,0 +1,7 @@
+module crib
+
+go 1.25.0
+
+require classic v0.0.0
+
+replace classic => ../classic
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+
+	"classic"
+)
+
+// trigramModel — условные вероятности (log10) буквы после одной и двух
+// предыдущих. Пробелов гамма-шифр не сохраняет, поэтому модель строится по
+// сплошной последовательности букв — как и встроенные таблицы квадграмм
+// classic, из которых она получается.
+type trigramModel struct {
+	n           int
+	uni, bi, tr []float64
+	random      float64 // средняя оценка буквы случайного текста
+	spread      float64 // разница средних оценок буквы текста языка и случайного
+}
+
+// newLanguageModel строит модель алфавита alpha по встроенной таблице
+// квадграмм его языка; для алфавитов без таблицы возвращает nil.
+func newLanguageModel(alpha classic.Alphabet) *trigramModel {
+	norm := func(r rune) rune {
+		if m, ok := alpha.Map[r]; ok {
+			return m
+		}
+		return r
+	}
+	q, err := classic.BuiltinQuadgrams(alpha.Name, string(alpha.Letters), norm)
+	if err != nil {
+		return nil
+	}
+	return newTrigramModel(q)
+}
+
+// newTrigramModel строит модель по частотам квадграмм. Невстреченные
+// сочетания получают откат («stupid backoff») к биграммам и частотам букв.
+func newTrigramModel(q *classic.Quadgrams) *trigramModel {
+	n := q.Size()
+	c1, c2, c3 := q.Marginal(1), q.Marginal(2), q.Marginal(3)
+	var total float64
+	for _, c := range c1 {
+		total += c
+	}
+
+	m := &trigramModel{n: n, uni: make([]float64, n), bi: make([]float64, n*n), tr: make([]float64, n*n*n)}
+	for c := range n {
+		m.uni[c] = math.Log10((c1[c] + 1) / (total + float64(n)))
+	}
+	for b := range n {
+		for c := range n {
+			if c2[b*n+c] > 0 {
+				m.bi[b*n+c] = math.Log10(c2[b*n+c] / c1[b])
+			} else {
+				m.bi[b*n+c] = math.Log10(0.4) + m.uni[c]
+			}
+		}
+	}
+	for ab := range n * n {
+		for c := range n {
+			if c3[ab*n+c] > 0 {
+				m.tr[ab*n+c] = math.Log10(c3[ab*n+c] / c2[ab])
+			} else {
+				m.tr[ab*n+c] = math.Log10(0.4) + m.bi[ab%n*n+c]
+			}
+		}
+	}
+
+	// калибровка: средняя оценка буквы языка (по частотам триграмм) и случайных букв
+	var lang float64
+	for i, v := range m.tr {
+		m.random += v / float64(len(m.tr))
+		lang += c3[i] / total * v
+	}
+	m.spread = max(lang-m.random, 0.1)
+	return m
+}
+
+// score — суммарная логарифмическая вероятность последовательности букв.
+func (m *trigramModel) score(text []int) float64 {
+	var sum float64
+	for i, c := range text {
+		switch i {
+		case 0:
+			sum += m.uni[c]
+		case 1:
+			sum += m.bi[text[0]*m.n+c]
+		default:
+			sum += m.tr[(text[i-2]*m.n+text[i-1])*m.n+c]
+		}
+	}
+	return sum
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"classic"
+)
+
+// process шифрует или дешифрует текст шифром четырёх квадратов.
+// key1 — ключ правой верхней таблицы, key2 — левой нижней.
+func process(text, key1, key2 string, squares classic.Squares, encrypt bool) (string, error) {
+	c, err := classic.NewFourSquare(classic.FourSquareKey{First: key1, Second: key2}, squares)
+	if err != nil {
+		return "", err
+	}
+	return classic.Process(c, text, encrypt)
+}
+
+// printRow выводит строки двух таблиц рядом.
+func printRow(left, right *classic.Table) {
+	for i := range left.Grid {
+		fmt.Print("    ")
+		for _, r := range left.Grid[i] {
+			fmt.Printf("%c ", r)
+		}
+		fmt.Print("  ")
+		for _, r := range right.Grid[i] {
+			fmt.Printf("%c ", r)
+		}
+		fmt.Println()
+	}
+}
+
+// printSquare выводит четыре таблицы одного алфавита квадратом 2×2.
+func printSquare(plain, t1, t2 *classic.Table) {
+	fmt.Println("  Открытая | Ключ 1")
+	printRow(plain, t1)
+	fmt.Println()
+	fmt.Println("  Ключ 2   | Открытая")
+	printRow(t2, plain)
+}
+
+func printTables(key1, key2 string, squares classic.Squares) {
+	fmt.Println("\nТаблицы шифра:")
+	c, err := classic.NewFourSquare(classic.FourSquareKey{First: key1, Second: key2}, squares)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	printSquare(c.LatPlain, c.Lat1, c.Lat2)
+	fmt.Println()
+	printSquare(c.CyrPlain, c.Cyr1, c.Cyr2)
+	fmt.Println()
+}
+
+// readKeys запрашивает ключи обеих ключевых таблиц.
+func readKeys() (string, string) {
+	key1 := strings.TrimSpace(classic.ReadLine("Введите ключ 1 (правой верхней таблицы): "))
+	key2 := strings.TrimSpace(classic.ReadLine("Введите ключ 2 (левой нижней таблицы):   "))
+	return key1, key2
+}
+
+// runFlags обрабатывает вход без меню (флаги -encrypt/-decrypt).
+func runFlags(sf *classic.StreamFlags, key2 string, squares classic.Squares) error {
+	c, err := classic.NewFourSquare(classic.FourSquareKey{First: sf.Key, Second: key2}, squares)
+	if err != nil {
+		return err
+	}
+	return sf.Run(c)
+}
+
+func main() {
+	sf := classic.RegisterStreamFlags(flag.CommandLine)
+	key2 := flag.String("key2", "", "ключ левой нижней таблицы; -key — ключ правой верхней")
+	loadSquares := classic.SquareFlags(flag.CommandLine)
+	flag.Parse()
+	squares, err := loadSquares()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+
+	if sf.Active() {
+		if err := runFlags(sf, *key2, squares); err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println()
+
+	for {
+		fmt.Println("Таблицы:", squares)
+		fmt.Println("1 — Зашифровать")
+		fmt.Println("2 — Расшифровать")
+		fmt.Println("3 — Показать таблицы по ключам")
+		fmt.Println("4 — Обработать файл")
+		fmt.Println("5 — Таблицы (размеры и объединения букв)")
+		fmt.Println("0 — Выход")
+		choice := strings.TrimSpace(classic.ReadLine(": "))
+
+		switch choice {
+		case "1", "2":
+			encrypt := choice == "1"
+			text := classic.ReadLine("Введите текст: ")
+			key1, key2 := readKeys()
+
+			result, err := process(text, key1, key2, squares, encrypt)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			fmt.Printf("\nРезультат: %s\n\n", result)
+
+		case "3":
+			key1, key2 := readKeys()
+			printTables(key1, key2, squares)
+
+		case "4":
+			job, err := classic.ReadFileJob()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			key1, key2 := readKeys()
+			c, err := classic.NewFourSquare(classic.FourSquareKey{First: key1, Second: key2}, squares)
+			if err == nil {
+				err = job.Run(c)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+
+		case "5":
+			sq, err := classic.ChooseSquares(squares)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			squares = sq
+			fmt.Println()
+
+		case "0":
+			fmt.Println("Выход.")
+			return
+
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
@@ -1,3 +1,7 @@
 module playfair
 
 go 1.25.0
+
+require classic v0.0.0
+
+replace classic => ../classic
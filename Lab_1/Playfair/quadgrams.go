@@ -0,0 +1,24 @@
+package main
+
+import "classic"
+
+// quadgramModel — таблица квадграмм алфавита таблицы Плейфейра
+// с логарифмическими вероятностями (log10) для оценки расшифровок.
+type quadgramModel struct {
+	*classic.Quadgrams
+	logp []float32
+}
+
+func newQuadgramModel(q *classic.Quadgrams) *quadgramModel {
+	return &quadgramModel{Quadgrams: q, logp: q.LogProbs()}
+}
+
+// score — суммарная логарифмическая вероятность квадграмм текста (больше — лучше).
+func (m *quadgramModel) score(text []int) float64 {
+	var s float64
+	n := m.Size()
+	for i := 0; i+3 < len(text); i++ {
+		s += float64(m.logp[((text[i]*n+text[i+1])*n+text[i+2])*n+text[i+3]])
+	}
+	return s
+}
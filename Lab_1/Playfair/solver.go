@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"classic"
+)
+
+// Вскрытие шифра Плейфейра без ключа методом имитации отжига:
+// таблица случайно изменяется (обмен ячеек, строк, столбцов, транспонирование),
+// а качество каждой таблицы оценивается по квадграммам расшифровки.
+
+// annealParams — параметры отжига.
+type annealParams struct {
+	tempStep   float64 // шаг понижения температуры
+	iterations int     // число изменений таблицы на каждой температуре
+	restarts   int     // число независимых запусков со случайной таблицы
+}
+
+// Один запуск находит ключ не всегда (для 300 букв — примерно в каждом
+// четвёртом), поэтому запусков несколько; поиск прекращается раньше, когда
+// лучшая оценка получена повторно.
+var defaultAnneal = annealParams{tempStep: 0.2, iterations: 10000, restarts: 10}
+
+// maxAnnealLetters — сколько первых букв шифртекста участвует в отжиге. Число
+// температур растёт с длиной текста, как и цена каждой оценки, поэтому без
+// ограничения время запуска росло бы квадратично; 500 букв с запасом хватает,
+// чтобы восстановить таблицу. Число чётное, чтобы не разрывать пары.
+const maxAnnealLetters = 500
+
+// startTemp — начальная температура для шифртекста из n букв. Расписание
+// (10 + 0.087·(n − 84), шаг 0.2) подобрано опытным путём для оценки суммой
+// log10 вероятностей квадграмм, поэтому модель должна быть именно такой таблицей.
+func startTemp(n int) float64 {
+	return 10 + 0.087*float64(n-84)
+}
+
+// grid — таблица для отжига: номера букв модели по ячейкам (i·cols + j) и обратно.
+type grid struct {
+	rows, cols int
+	cell       []int // ячейка → номер буквы
+	pos        []int // номер буквы → ячейка
+}
+
+// randomGrid заполняет таблицу случайной перестановкой букв.
+func randomGrid(rows, cols int, rng *rand.Rand) *grid {
+	g := &grid{rows: rows, cols: cols, cell: rng.Perm(rows * cols), pos: make([]int, rows*cols)}
+	for c, l := range g.cell {
+		g.pos[l] = c
+	}
+	return g
+}
+
+func (g *grid) clone() *grid {
+	return &grid{rows: g.rows, cols: g.cols, cell: slices.Clone(g.cell), pos: slices.Clone(g.pos)}
+}
+
+func (g *grid) swap(c1, c2 int) {
+	a, b := g.cell[c1], g.cell[c2]
+	g.cell[c1], g.cell[c2] = b, a
+	g.pos[a], g.pos[b] = c2, c1
+}
+
+func (g *grid) swapRows(i1, i2 int) {
+	for j := 0; j < g.cols; j++ {
+		g.swap(i1*g.cols+j, i2*g.cols+j)
+	}
+}
+
+func (g *grid) swapCols(j1, j2 int) {
+	for i := 0; i < g.rows; i++ {
+		g.swap(i*g.cols+j1, i*g.cols+j2)
+	}
+}
+
+// transpose отражает квадратную таблицу относительно главной диагонали.
+func (g *grid) transpose() {
+	for i := 0; i < g.rows; i++ {
+		for j := i + 1; j < g.cols; j++ {
+			g.swap(i*g.cols+j, j*g.cols+i)
+		}
+	}
+}
+
+// decBigram дешифрует пару по правилам Плейфейра (см. classic.PlayfairTable.DecBigram).
+func (g *grid) decBigram(a, b int) (int, int) {
+	pa, pb := g.pos[a], g.pos[b]
+	ra, ca, rb, cb := pa/g.cols, pa%g.cols, pb/g.cols, pb%g.cols
+	switch {
+	case ra == rb:
+		return g.cell[ra*g.cols+(ca+g.cols-1)%g.cols], g.cell[rb*g.cols+(cb+g.cols-1)%g.cols]
+	case ca == cb:
+		return g.cell[(ra+g.rows-1)%g.rows*g.cols+ca], g.cell[(rb+g.rows-1)%g.rows*g.cols+cb]
+	default:
+		return g.cell[ra*g.cols+cb], g.cell[rb*g.cols+ca]
+	}
+}
+
+// table строит classic.Table с буквами алфавита модели.
+func (g *grid) table(alphabet string) *classic.Table {
+	letters := []rune(alphabet)
+	key := make([]rune, len(g.cell))
+	for c, l := range g.cell {
+		key[c] = letters[l]
+	}
+	return classic.BuildTable(string(key), g.rows, g.cols, alphabet, func(r rune) rune { return r })
+}
+
+// mutate вносит в таблицу случайное изменение и возвращает функцию его отмены
+// (все изменения — инволюции, отмена повторяет то же действие).
+func mutate(g *grid, rng *rand.Rand) func() {
+	switch k := rng.Intn(50); {
+	case k == 0 && g.rows == g.cols:
+		g.transpose()
+		return g.transpose
+	case k < 3:
+		i1, i2 := rng.Intn(g.rows), rng.Intn(g.rows)
+		g.swapRows(i1, i2)
+		return func() { g.swapRows(i1, i2) }
+	case k < 6:
+		j1, j2 := rng.Intn(g.cols), rng.Intn(g.cols)
+		g.swapCols(j1, j2)
+		return func() { g.swapCols(j1, j2) }
+	default:
+		c1, c2 := rng.Intn(len(g.cell)), rng.Intn(len(g.cell))
+		g.swap(c1, c2)
+		return func() { g.swap(c1, c2) }
+	}
+}
+
+// decryptScore расшифровывает шифртекст (номера букв модели) таблицей и оценивает результат.
+func decryptScore(g *grid, cipher []int, m *quadgramModel, buf []int) float64 {
+	for i := 0; i < len(cipher); i += 2 {
+		buf[i], buf[i+1] = g.decBigram(cipher[i], cipher[i+1])
+	}
+	return m.score(buf)
+}
+
+// anneal выполняет один запуск имитации отжига и возвращает лучшую найденную таблицу.
+func anneal(cipher []int, rows, cols int, m *quadgramModel, p annealParams, rng *rand.Rand) (*grid, float64) {
+	buf := make([]int, len(cipher))
+	parent := randomGrid(rows, cols, rng)
+	parentScore := decryptScore(parent, cipher, m, buf)
+	best, bestScore := parent.clone(), parentScore
+
+	for temp := startTemp(len(cipher)); temp >= 0; temp -= p.tempStep {
+		for i := 0; i < p.iterations; i++ {
+			undo := mutate(parent, rng)
+			s := decryptScore(parent, cipher, m, buf)
+			d := s - parentScore
+			if d >= 0 || (temp > 0 && rng.Float64() < math.Exp(d/temp)) {
+				parentScore = s
+				if s > bestScore {
+					best, bestScore = parent.clone(), s
+				}
+			} else {
+				undo()
+			}
+		}
+	}
+	return best, bestScore
+}
+
+// solveProgress получает итог каждого запуска: номер, оценку на букву и признак
+// того, что лучшая оценка повторилась и поиск останавливается.
+type solveProgress func(run int, score float64, repeated bool)
+
+// solveStream подбирает таблицу для одного потока букв (латиницы или кириллицы);
+// progress (может быть nil) вызывается после каждого запуска.
+func solveStream(letters []rune, rows, cols int, alphabet string, m *quadgramModel, p annealParams, rng *rand.Rand, progress solveProgress) (*classic.Table, float64, error) {
+	cipher := make([]int, 0, len(letters))
+	for _, r := range letters {
+		cipher = append(cipher, m.Index(r))
+	}
+	if len(cipher)%2 != 0 {
+		return nil, 0, fmt.Errorf("длина зашифрованного текста должна быть чётной (шифр Плейфейра работает с парами)")
+	}
+
+	sample := cipher
+	if len(sample) > maxAnnealLetters {
+		sample = sample[:maxAnnealLetters]
+	}
+
+	var best *grid
+	bestScore := math.Inf(-1)
+	for run := 1; run <= p.restarts; run++ {
+		g, s := anneal(sample, rows, cols, m, p, rng)
+		// независимые запуски дали одну и ту же расшифровку (таблицы
+		// эквивалентны) — почти наверняка это и есть ключ
+		repeated := s == bestScore
+		if progress != nil {
+			progress(run, s/float64(len(sample)), repeated)
+		}
+		if repeated {
+			break
+		}
+		if s > bestScore {
+			best, bestScore = g, s
+		}
+	}
+	score := decryptScore(best, cipher, m, make([]int, len(cipher)))
+	return best.table(alphabet), score / float64(len(cipher)), nil
+}
+
+// chooseModel загружает таблицу квадграмм из файла или встроенную таблицу
+// языка lang (см. classic.BuiltinQuadgrams).
+func chooseModel(path, lang, alphabet string, norm func(rune) rune) (*quadgramModel, error) {
+	var (
+		q   *classic.Quadgrams
+		err error
+	)
+	if path == "" {
+		q, err = classic.BuiltinQuadgrams(lang, alphabet, norm)
+	} else {
+		q, err = classic.LoadQuadgrams(path, alphabet, norm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newQuadgramModel(q), nil
+}
+
+// printProgress выводит итог запуска отжига (см. solveProgress).
+func printProgress(run int, score float64, repeated bool) {
+	fmt.Printf("    запуск %d: оценка %.2f\n", run, score)
+	if repeated {
+		fmt.Println("    лучшая оценка повторилась, поиск остановлен")
+	}
+}
+
+// runSolver — диалог вскрытия шифртекста без ключа для таблиц squares.
+func runSolver(squares classic.Squares) {
+	text := classic.ReadLine("Введите шифртекст: ")
+
+	latLetters, cyrLetters := classic.Streams(squares.Tokenize(text))
+	if len(latLetters) == 0 && len(cyrLetters) == 0 {
+		fmt.Println("Ошибка: в тексте нет букв")
+		return
+	}
+
+	p := defaultAnneal
+	if s := strings.TrimSpace(classic.ReadLine(fmt.Sprintf("Число запусков [%d]: ", p.restarts))); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			fmt.Println("Ошибка: ожидается положительное число")
+			return
+		}
+		p.restarts = n
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	streams := []struct {
+		title   string
+		letters []rune
+		sq      classic.Square
+		lang    string
+	}{
+		{"Латиница", latLetters, squares[0], "latin"},
+		{"Кириллица", cyrLetters, squares[1], "russian"},
+	}
+
+	var key strings.Builder
+	for _, s := range streams {
+		if len(s.letters) == 0 {
+			continue
+		}
+		title := s.title + " " + s.sq.String()
+		path := strings.TrimSpace(classic.ReadLine(title + ": файл квадграмм (пусто — встроенная таблица): "))
+		m, err := chooseModel(path, s.lang, s.sq.Letters, s.sq.Norm)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			return
+		}
+		fmt.Printf("\n  %s, %d букв:\n", title, len(s.letters))
+		t, score, err := solveStream(s.letters, s.sq.Rows, s.sq.Cols, s.sq.Letters, m, p, rng, printProgress)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			return
+		}
+		fmt.Printf("  Лучшая оценка на букву: %.2f\n", score)
+		t.Print("Найденная таблица")
+		key.WriteString(t.Key())
+	}
+
+	result, err := process(text, key.String(), settings{fillers: classic.FillersStrip, squares: squares}, false)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	fmt.Println("\nКлюч (таблицы построчно):", key.String())
+	fmt.Println("Расшифрованный текст:", result)
+	fmt.Println()
+}
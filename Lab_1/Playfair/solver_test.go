@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"classic"
+)
+
+// Начало «Повести о двух городах» Диккенса — 475 букв.
+const dickensPassage = `It was the best of times, it was the worst of times, it was the age of
+wisdom, it was the age of foolishness, it was the epoch of belief, it was the
+epoch of incredulity, it was the season of Light, it was the season of
+Darkness, it was the spring of hope, it was the winter of despair, we had
+everything before us, we had nothing before us, we were all going direct to
+Heaven, we were all going direct the other way - in short, the period was so
+far like the present period, that some of its noisiest authorities insisted on
+its being received, for good or for evil, in the superlative degree of
+comparison only.`
+
+// Начало «Анны Карениной» Толстого — 482 буквы.
+const tolstoyPassage = `Все счастливые семьи похожи друг на друга, каждая несчастливая семья
+несчастлива по-своему. Всё смешалось в доме Облонских. Жена узнала, что муж был
+в связи с бывшею в их доме француженкою-гувернанткой, и объявила мужу, что не
+может жить с ним в одном доме. Положение это продолжалось уже третий день и
+мучительно чувствовалось и самими супругами, и всеми членами семьи, и
+домочадцами. Все члены семьи и домочадцы чувствовали, что нет смысла в их
+сожительстве и что на каждом постоялом дворе случайно сошедшиеся люди более
+связаны между собой, чем они, члены семьи и домочадцы Облонских.`
+
+// solverSeed — начальное значение генератора: с ним тест детерминирован.
+const solverSeed = 2
+
+// TestSolveStreamRecoversKey проверяет восстановление таблицы латиницы 5×5
+// и кириллицы 4×8 по одному шифртексту.
+func TestSolveStreamRecoversKey(t *testing.T) {
+	if testing.Short() {
+		t.Skip("отжиг занимает несколько секунд")
+	}
+	tests := []struct {
+		lang   string
+		key    classic.PlayfairKey
+		text   string
+		stream int // 0 — латиница, 1 — кириллица
+	}{
+		{"latin", "monarchy", dickensPassage, 0},
+		{"russian", "шифровальщик", tolstoyPassage, 1},
+	}
+	for _, tt := range tests {
+		sq := classic.DefaultSquares[tt.stream]
+		p, err := classic.NewPlayfair(tt.key, classic.Squares{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := p.Encrypt(tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lat, cyr := classic.Streams(classic.DefaultSquares.Tokenize(enc))
+		cipher, table := lat, p.Lat
+		if tt.stream == 1 {
+			cipher, table = cyr, p.Cyr
+		}
+		m, err := chooseModel("", tt.lang, sq.Letters, sq.Norm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// на тексте такой длины ключ обычно находит первый же запуск
+		p1 := defaultAnneal
+		p1.restarts = 1
+		found, _, err := solveStream(cipher, sq.Rows, sq.Cols, sq.Letters, m, p1, rand.New(rand.NewSource(solverSeed)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Таблицы, отличающиеся циклическим сдвигом строк или столбцов, расшифровывают
+		// одинаково, поэтому сравниваются расшифровки, а не сами таблицы.
+		want, _ := classic.PlayfairStream(cipher, table, sq, false)
+		got, _ := classic.PlayfairStream(cipher, classic.PlayfairTable{Table: found}, sq, false)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: таблица %q не восстанавливает ключ %s:\n%s", sq, found.Key(), tt.key, string(got))
+		}
+	}
+}
+
+// TestBuiltinQuadgrams проверяет, что встроенные таблицы отличают текст
+// языка от той же последовательности букв в обратном порядке.
+func TestBuiltinQuadgrams(t *testing.T) {
+	tests := []struct {
+		sq   classic.Square
+		lang string
+		text string
+	}{
+		{classic.DefaultSquares[0], "latin", "the quick brown fox jumps over the lazy dog"},
+		{classic.DefaultSquares[1], "russian", "съешь же ещё этих мягких французских булок да выпей чаю"},
+	}
+	for _, tt := range tests {
+		m, err := chooseModel("", tt.lang, tt.sq.Letters, tt.sq.Norm)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.sq, err)
+		}
+		text := m.Indices(tt.text)
+		reversed := slices.Clone(text)
+		slices.Reverse(reversed)
+		if m.score(text) <= m.score(reversed) {
+			t.Errorf("%s: оценка текста %.1f не выше обратного %.1f", tt.sq, m.score(text), m.score(reversed))
+		}
+	}
+}
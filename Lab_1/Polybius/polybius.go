@@ -0,0 +1,285 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"classic"
+)
+
+// Шифры семейства квадрата Полибия: координаты, Бифид, Трифид и ADFGVX.
+
+// kinds — шифры в порядке меню и их имена для флага -cipher.
+var kinds = []struct {
+	name, title string
+	squares     classic.Squares // таблицы по умолчанию
+}{
+	{"polybius", "квадрат Полибия (координаты)", classic.DefaultSquares},
+	{"bifid", "Бифид", classic.BifidSquares},
+	{"trifid", "Трифид (куб 3×3×3)", classic.TrifidSquares},
+	{"adfgvx", "ADFGVX (с перестановкой столбцов)", classic.ADFGVXSquares},
+}
+
+// settings — шифр и его параметры, выбранные в меню или флагами.
+type settings struct {
+	kind    int
+	period  int             // период Бифида и Трифида (0 — всё сообщение)
+	squares classic.Squares // нулевые таблицы — таблицы шифра по умолчанию
+}
+
+func (s settings) String() string {
+	str := kinds[s.kind].title
+	if name := kinds[s.kind].name; name == "bifid" || name == "trifid" {
+		if s.period == 0 {
+			str += ", период — всё сообщение"
+		} else {
+			str += fmt.Sprintf(", период %d", s.period)
+		}
+	}
+	return str
+}
+
+// tables возвращает таблицы, которыми будет пользоваться шифр.
+func (s settings) tables() classic.Squares {
+	return s.squares.Or(kinds[s.kind].squares)
+}
+
+// newCipher строит выбранный шифр. key — ключ таблиц, key2 — ключ
+// перестановки столбцов (только ADFGVX).
+func (s settings) newCipher(key, key2 string) (classic.Streamer, error) {
+	switch kinds[s.kind].name {
+	case "bifid":
+		return classic.NewBifid(classic.PolybiusKey(key), s.period, s.squares)
+	case "trifid":
+		return classic.NewTrifid(classic.PolybiusKey(key), s.period, s.squares)
+	case "adfgvx":
+		return classic.NewADFGVX(classic.ADFGVXKey{Square: key, Transposition: key2}, s.squares)
+	default:
+		return classic.NewPolybius(classic.PolybiusKey(key), s.squares)
+	}
+}
+
+// readKeys запрашивает ключ таблиц и, для ADFGVX, ключ перестановки.
+func (s settings) readKeys() (string, string) {
+	key := strings.TrimSpace(classic.ReadLine("Ключ таблиц (пусто — по алфавиту): "))
+	var key2 string
+	if kinds[s.kind].name == "adfgvx" {
+		key2 = strings.TrimSpace(classic.ReadLine("Ключ перестановки столбцов:        "))
+	}
+	return key, key2
+}
+
+// printGrid выводит таблицу с подписями строк и столбцов.
+func printGrid(title string, t *classic.Table, rowLabel, colLabel func(int) string) {
+	fmt.Printf("  %s:\n", title)
+	fmt.Print("     ")
+	for j := range t.Cols {
+		fmt.Printf("%s ", colLabel(j))
+	}
+	fmt.Println()
+	for i, row := range t.Grid {
+		fmt.Printf("    %s", rowLabel(i))
+		for _, r := range row {
+			fmt.Printf("%c ", r)
+		}
+		fmt.Println()
+	}
+}
+
+// number возвращает подпись координаты — номер, начиная с единицы.
+func number(i int) string { return strconv.Itoa(i + 1) }
+
+// printCube выводит таблицу Трифида 3×9 как три слоя 3×3 рядом.
+func printCube(title string, t *classic.Table) {
+	fmt.Printf("  %s (слой, строка, столбец):\n", title)
+	fmt.Println("      слой 1     слой 2     слой 3")
+	for i := range 3 {
+		fmt.Printf("    %d ", i+1)
+		for layer := range 3 {
+			for j := range 3 {
+				fmt.Printf("%c ", t.Grid[layer][i*3+j])
+			}
+			fmt.Print("    ")
+		}
+		fmt.Println()
+	}
+}
+
+func printTables(key, key2 string, s settings) {
+	c, err := s.newCipher(key, key2)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	fmt.Println("\nТаблицы шифра:", s)
+	sq := s.tables()
+	switch c := c.(type) {
+	case *classic.Polybius:
+		for i, t := range c.Tables {
+			printGrid(sq[i].String(), t, func(r int) string { return strconv.Itoa(c.RowLabel(i, r)) + " " }, number)
+		}
+	case *classic.Fractionation:
+		for i, t := range c.Tables {
+			if c.Cube {
+				printCube(sq[i].String(), t)
+				continue
+			}
+			printGrid(sq[i].String(), t, func(r int) string { return number(r) + " " }, number)
+		}
+	case *classic.ADFGVX:
+		for i, t := range c.Tables {
+			label := func(j int) string { return strings.ToUpper(string(c.Labels[i][j])) }
+			printGrid(sq[i].String(), t, func(r int) string { return label(r) + " " }, label)
+		}
+		order := make([]string, len(c.Order))
+		for k, j := range c.Order {
+			order[k] = strconv.Itoa(j + 1)
+		}
+		fmt.Println("  Порядок чтения столбцов:", strings.Join(order, " "))
+	}
+	fmt.Println()
+}
+
+// chooseKind — диалог выбора шифра и периода.
+func chooseKind(current settings) settings {
+	fmt.Println("Шифр:")
+	for i, k := range kinds {
+		fmt.Printf("  %d — %s\n", i+1, k.title)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine(": ")))
+	if err != nil || n < 1 || n > len(kinds) {
+		fmt.Println("Неверный выбор, шифр не изменён.")
+		return current
+	}
+	s := current
+	if s.kind != n-1 {
+		s.kind, s.squares = n-1, classic.Squares{} // таблицы прежнего шифра могут не подойти
+	}
+	if name := kinds[s.kind].name; name == "bifid" || name == "trifid" {
+		p, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine("Период (0 — всё сообщение одним блоком): ")))
+		if err != nil || p < 0 {
+			fmt.Println("Неверный период, оставлен", s.period)
+		} else {
+			s.period = p
+		}
+	}
+	fmt.Println("Выбран шифр:", s)
+	fmt.Println()
+	return s
+}
+
+// runFlags обрабатывает вход без меню (флаги -encrypt/-decrypt).
+func runFlags(sf *classic.StreamFlags, key2 string, s settings) error {
+	c, err := s.newCipher(sf.Key, key2)
+	if err != nil {
+		return err
+	}
+	return sf.Run(c)
+}
+
+func main() {
+	sf := classic.RegisterStreamFlags(flag.CommandLine)
+	kindName := flag.String("cipher", "polybius", "шифр: polybius, bifid, trifid или adfgvx")
+	period := flag.Int("period", 5, "период Бифида и Трифида (0 — всё сообщение)")
+	key2 := flag.String("key2", "", "ключ перестановки столбцов ADFGVX; -key — ключ таблиц")
+	loadSquares := classic.OptionalSquareFlags(flag.CommandLine)
+	flag.Parse()
+
+	s := settings{kind: -1, period: *period}
+	for i, k := range kinds {
+		if k.name == *kindName {
+			s.kind = i
+		}
+	}
+	squares, err := loadSquares()
+	if err == nil && s.kind < 0 {
+		err = fmt.Errorf("неизвестный шифр %q", *kindName)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+	s.squares = squares
+
+	if sf.Active() {
+		if err := runFlags(sf, *key2, s); err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println()
+
+	for {
+		fmt.Println("Шифр:", s)
+		fmt.Println("Таблицы:", s.tables())
+		fmt.Println("1 — Зашифровать")
+		fmt.Println("2 — Расшифровать")
+		fmt.Println("3 — Показать таблицы по ключу")
+		fmt.Println("4 — Выбрать шифр")
+		fmt.Println("5 — Обработать файл")
+		fmt.Println("6 — Таблицы (размеры и объединения букв)")
+		fmt.Println("0 — Выход")
+		choice := strings.TrimSpace(classic.ReadLine(": "))
+
+		switch choice {
+		case "1", "2":
+			encrypt := choice == "1"
+			text := classic.ReadLine("Введите текст: ")
+			key, key2 := s.readKeys()
+
+			c, err := s.newCipher(key, key2)
+			var result string
+			if err == nil {
+				result, err = classic.Process(c, text, encrypt)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			fmt.Printf("\nРезультат: %s\n\n", result)
+
+		case "3":
+			key, key2 := s.readKeys()
+			printTables(key, key2, s)
+
+		case "4":
+			s = chooseKind(s)
+
+		case "5":
+			job, err := classic.ReadFileJob()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			key, key2 := s.readKeys()
+			c, err := s.newCipher(key, key2)
+			if err == nil {
+				err = job.Run(c)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+
+		case "6":
+			sq, err := classic.ChooseSquares(s.tables())
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			s.squares = sq
+			fmt.Println()
+
+		case "0":
+			fmt.Println("Выход.")
+			return
+
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
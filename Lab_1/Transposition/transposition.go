@@ -0,0 +1,273 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"classic"
+)
+
+// Перестановочные шифры (столбцовая и двойная перестановка) и составной шифр:
+// замена из Lab_1, затем перестановка; расшифрование — в обратном порядке.
+
+// substitutions — шифры замены, которые можно поставить перед перестановкой,
+// в порядке меню и их имена для флага -sub.
+var substitutions = []struct{ name, title string }{
+	{"none", "без замены"},
+	{"vigenere", "Виженер"},
+	{"playfair", "Плейфейр"},
+	{"twosquare", "двойной квадрат"},
+}
+
+// settings — выбранные перестановка и замена.
+type settings struct {
+	double bool // двойная перестановка
+	sub    int  // индекс в substitutions
+}
+
+func (s settings) String() string {
+	str := "столбцовая перестановка"
+	if s.double {
+		str = "двойная перестановка"
+	}
+	if s.sub > 0 {
+		str = substitutions[s.sub].title + " + " + str
+	}
+	return str
+}
+
+// keys — ключи перестановок (trans) и шифра замены (sub).
+type keys struct {
+	trans [2]string
+	sub   [2]string
+}
+
+// readKeys запрашивает ключи, нужные выбранным шифрам.
+func (s settings) readKeys() keys {
+	var k keys
+	if s.double {
+		k.trans[0] = strings.TrimSpace(classic.ReadLine("Ключ первой перестановки:  "))
+		k.trans[1] = strings.TrimSpace(classic.ReadLine("Ключ второй перестановки:  "))
+	} else {
+		k.trans[0] = strings.TrimSpace(classic.ReadLine("Ключ перестановки:         "))
+	}
+	switch substitutions[s.sub].name {
+	case "vigenere", "playfair":
+		k.sub[0] = strings.TrimSpace(classic.ReadLine("Ключ шифра замены:         "))
+	case "twosquare":
+		k.sub[0] = strings.TrimSpace(classic.ReadLine("Ключ 1 двойного квадрата:  "))
+		k.sub[1] = strings.TrimSpace(classic.ReadLine("Ключ 2 двойного квадрата:  "))
+	}
+	return k
+}
+
+// newTransposition строит перестановку по ключам.
+func (s settings) newTransposition(k keys) (*classic.Transposition, error) {
+	if s.double {
+		return classic.NewDoubleTransposition(classic.DoubleTranspositionKey{First: k.trans[0], Second: k.trans[1]})
+	}
+	return classic.NewColumnar(classic.TranspositionKey(k.trans[0]))
+}
+
+// newCipher строит перестановку или, если выбрана замена, составной шифр.
+func (s settings) newCipher(k keys) (classic.Streamer, error) {
+	t, err := s.newTransposition(k)
+	if err != nil {
+		return nil, err
+	}
+	var sub classic.Streamer
+	switch substitutions[s.sub].name {
+	case "vigenere":
+		sub, err = classic.NewVigenere(classic.VigenereKey(k.sub[0]), nil)
+	case "playfair":
+		sub, err = classic.NewPlayfair(classic.PlayfairKey(k.sub[0]), classic.Squares{})
+	case "twosquare":
+		sub, err = classic.NewTwoSquare(classic.TwoSquareKey{Left: k.sub[0], Right: k.sub[1]}, classic.Variant{}, classic.Squares{})
+	default:
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return classic.NewProduct(sub, t)
+}
+
+// printGrid выводит буквы, записанные по строкам под ключом, и номера
+// столбцов в порядке чтения.
+func printGrid(key string, order []int, letters []rune) {
+	header := classic.FilterKey(key)
+	rank := make([]int, len(order))
+	for k, j := range order {
+		rank[j] = k + 1
+	}
+	fmt.Print("    ")
+	for _, r := range header {
+		fmt.Printf("%3c", unicode.ToUpper(r))
+	}
+	fmt.Print("\n    ")
+	for _, n := range rank {
+		fmt.Printf("%3d", n)
+	}
+	fmt.Println()
+	for i := 0; i < len(letters); i += len(order) {
+		fmt.Print("    ")
+		for _, r := range letters[i:min(i+len(order), len(letters))] {
+			fmt.Printf("%3c", r)
+		}
+		fmt.Println()
+	}
+}
+
+// showTables показывает таблицы перестановки для текста (буквы после замены,
+// если она выбрана).
+func showTables(text string, s settings, k keys) {
+	c, err := s.newCipher(k)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	if p, ok := c.(classic.Product); ok {
+		if text, err = p[0].Encrypt(text); err != nil {
+			fmt.Println("Ошибка:", err)
+			return
+		}
+		fmt.Println("\nПосле замены:", text)
+	}
+	t, _ := s.newTransposition(k)
+
+	var letters []rune
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	for i, order := range t.Orders {
+		fmt.Printf("\nПерестановка %d:\n", i+1)
+		printGrid(k.trans[i], order, letters)
+		letters = classic.ColumnarEncrypt(letters, order)
+		fmt.Println("  Чтение по столбцам:", string(letters))
+	}
+	fmt.Println()
+}
+
+// chooseSettings — диалог выбора перестановки и замены.
+func chooseSettings(current settings) settings {
+	s := current
+	fmt.Println("Перестановка:")
+	fmt.Println("  1 — Столбцовая")
+	fmt.Println("  2 — Двойная")
+	switch strings.TrimSpace(classic.ReadLine(": ")) {
+	case "1":
+		s.double = false
+	case "2":
+		s.double = true
+	default:
+		fmt.Println("Неверный выбор, перестановка не изменена.")
+	}
+	fmt.Println("Шифр замены перед перестановкой:")
+	for i, sub := range substitutions {
+		fmt.Printf("  %d — %s\n", i+1, sub.title)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine(": ")))
+	if err != nil || n < 1 || n > len(substitutions) {
+		fmt.Println("Неверный выбор, замена не изменена.")
+	} else {
+		s.sub = n - 1
+	}
+	fmt.Println("Выбрано:", s)
+	fmt.Println()
+	return s
+}
+
+func main() {
+	sf := classic.RegisterStreamFlags(flag.CommandLine)
+	key2 := flag.String("key2", "", "ключ второй перестановки (задаёт двойную перестановку); -key — ключ первой")
+	subName := flag.String("sub", "none", "шифр замены перед перестановкой: none, vigenere, playfair, twosquare")
+	subKey := flag.String("skey", "", "ключ шифра замены (для двойного квадрата — ключ 1)")
+	subKey2 := flag.String("skey2", "", "ключ 2 двойного квадрата")
+	flag.Parse()
+
+	s := settings{double: *key2 != "", sub: -1}
+	for i, sub := range substitutions {
+		if sub.name == *subName {
+			s.sub = i
+		}
+	}
+	if s.sub < 0 {
+		fmt.Fprintln(os.Stderr, "Ошибка:", fmt.Errorf("неизвестный шифр замены %q", *subName))
+		os.Exit(1)
+	}
+
+	if sf.Active() {
+		c, err := s.newCipher(keys{trans: [2]string{sf.Key, *key2}, sub: [2]string{*subKey, *subKey2}})
+		if err == nil {
+			err = sf.Run(c)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println()
+
+	for {
+		fmt.Println("Шифр:", s)
+		fmt.Println("1 — Зашифровать")
+		fmt.Println("2 — Расшифровать")
+		fmt.Println("3 — Показать таблицы перестановки")
+		fmt.Println("4 — Выбрать перестановку и замену")
+		fmt.Println("5 — Обработать файл")
+		fmt.Println("0 — Выход")
+		choice := strings.TrimSpace(classic.ReadLine(": "))
+
+		switch choice {
+		case "1", "2":
+			encrypt := choice == "1"
+			text := classic.ReadLine("Введите текст: ")
+			c, err := s.newCipher(s.readKeys())
+			var result string
+			if err == nil {
+				result, err = classic.Process(c, text, encrypt)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			fmt.Printf("\nРезультат: %s\n\n", result)
+
+		case "3":
+			text := classic.ReadLine("Открытый текст: ")
+			showTables(text, s, s.readKeys())
+
+		case "4":
+			s = chooseSettings(s)
+
+		case "5":
+			job, err := classic.ReadFileJob()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			c, err := s.newCipher(s.readKeys())
+			if err == nil {
+				err = job.Run(c)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+
+		case "0":
+			fmt.Println("Выход.")
+			return
+
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"unicode"
+
+	"classic"
+)
+
+const (
+	maxKeyLen     = 20   // наибольшая проверяемая длина ключа
+	lengthPenalty = 0.03 // штраф за каждую букву длины ключа при ранжировании
+	kasiskiWeight = 0.25 // вес доли расстояний Касиски, кратных длине
+	minFreq       = 0.5  // нижняя граница ожидаемой частоты (%) для χ², чтобы редкие ё/ъ не доминировали
+	lengthsToTry  = 5    // сколько лучших длин ключа разбирать подробно
+	previewLength = 60   // длина фрагмента расшифровки в отчёте
+)
+
+// streamLetter — буква шифртекста: позиция в своём алфавите и его размер.
+// Ключ в process сдвигается только по буквам, поэтому анализ ведётся по этому потоку.
+type streamLetter struct {
+	pos int
+	n   int
+}
+
+// alphabetPos возвращает позицию буквы в её алфавите (см. classic.AlphabetFor) или -1.
+func alphabetPos(r rune) (int, []rune) {
+	alpha := classic.AlphabetFor(r)
+	lr := unicode.ToLower(r)
+	for i, c := range alpha {
+		if c == lr {
+			return i, alpha
+		}
+	}
+	return -1, nil
+}
+
+func lettersOf(text string) []streamLetter {
+	var letters []streamLetter
+	for _, r := range []rune(text) {
+		if pos, alpha := alphabetPos(r); pos >= 0 {
+			letters = append(letters, streamLetter{pos: pos, n: len(alpha)})
+		}
+	}
+	return letters
+}
+
+// freqFor возвращает таблицу частот языка для алфавита размера n.
+func freqFor(n int) []float64 {
+	if n == len(classic.EnglishFreq) {
+		return classic.EnglishFreq
+	}
+	return classic.RussianFreq
+}
+
+// kasiskiDistances находит повторяющиеся триграммы и расстояния между соседними вхождениями.
+func kasiskiDistances(letters []streamLetter) []int {
+	last := make(map[[3]streamLetter]int)
+	var distances []int
+	for i := 0; i+3 <= len(letters); i++ {
+		gram := [3]streamLetter{letters[i], letters[i+1], letters[i+2]}
+		if prev, ok := last[gram]; ok {
+			distances = append(distances, i-prev)
+		}
+		last[gram] = i
+	}
+	return distances
+}
+
+// columnCounts считает буквы столбца col (каждая keyLen-я буква) раздельно по алфавитам,
+// предварительно сдвигая их назад на shift, как при дешифровании.
+func columnCounts(letters []streamLetter, col, keyLen, shift int) map[int][]int {
+	counts := make(map[int][]int) // размер алфавита - счётчики букв
+	for i := col; i < len(letters); i += keyLen {
+		l := letters[i]
+		if counts[l.n] == nil {
+			counts[l.n] = make([]int, l.n)
+		}
+		counts[l.n][(l.pos-shift%l.n+l.n)%l.n]++
+	}
+	return counts
+}
+
+// columnIC считает средний индекс совпадений столбцов при длине ключа keyLen.
+// Латиница и кириллица в столбце учитываются раздельно, итог взвешивается по числу букв.
+func columnIC(letters []streamLetter, keyLen int) float64 {
+	var weighted, total float64
+	for col := 0; col < keyLen; col++ {
+		for _, c := range columnCounts(letters, col, keyLen, 0) {
+			var n, sum int
+			for _, v := range c {
+				n += v
+				sum += v * (v - 1)
+			}
+			if n < 2 {
+				continue
+			}
+			weighted += float64(sum) / float64(n-1)
+			total += float64(n)
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// languageIC возвращает ожидаемые индексы совпадений открытого и случайного текста
+// с учётом доли латиницы и кириллицы во входе.
+func languageIC(letters []streamLetter) (plain, random float64) {
+	for _, l := range letters {
+		plain += classic.ExpectedIC(freqFor(l.n))
+		random += 1 / float64(l.n)
+	}
+	n := float64(len(letters))
+	return plain / n, random / n
+}
+
+// chiSquared оценивает близость распределения букв к частотам языка (меньше — лучше).
+func chiSquared(counts []int, freq []float64) float64 {
+	var n int
+	for _, c := range counts {
+		n += c
+	}
+	var chi float64
+	for i, c := range counts {
+		exp := float64(n) * math.Max(freq[i], minFreq) / 100
+		d := float64(c) - exp
+		chi += d * d / exp
+	}
+	return chi
+}
+
+// keyCandidates — возможные буквы ключа; сначала идёт алфавит, преобладающий в тексте,
+// чтобы при равных сдвигах выбиралась буква «родного» алфавита.
+func keyCandidates(letters []streamLetter) []rune {
+	lat := 0
+	for _, l := range letters {
+		if l.n == len([]rune(classic.LatinAlphabet)) {
+			lat++
+		}
+	}
+	if 2*lat >= len(letters) {
+		return []rune(classic.LatinAlphabet + classic.CyrillicAlphabet)
+	}
+	return []rune(classic.CyrillicAlphabet + classic.LatinAlphabet)
+}
+
+// bestKeyRune подбирает букву ключа для столбца минимизацией χ².
+// Сдвиг применяется по модулю алфавита каждой буквы текста, как в classic.ShiftRune.
+func bestKeyRune(letters []streamLetter, col, keyLen int, candidates []rune) rune {
+	best, bestChi := candidates[0], math.Inf(1)
+	for _, k := range candidates {
+		shift, _ := alphabetPos(k)
+		var chi float64
+		for n, c := range columnCounts(letters, col, keyLen, shift) {
+			chi += chiSquared(c, freqFor(n))
+		}
+		if chi < bestChi {
+			best, bestChi = k, chi
+		}
+	}
+	return best
+}
+
+// shortestPeriod сворачивает ключ, состоящий из повторов более короткого ключа.
+func shortestPeriod(key []rune) []rune {
+	for p := 1; p < len(key); p++ {
+		if len(key)%p != 0 {
+			continue
+		}
+		ok := true
+		for i := p; i < len(key) && ok; i++ {
+			ok = key[i] == key[i-p]
+		}
+		if ok {
+			return key[:p]
+		}
+	}
+	return key
+}
+
+// textScore — χ² расшифрованного текста на одну букву (меньше — ближе к языку).
+func textScore(text string) float64 {
+	letters := lettersOf(text)
+	if len(letters) == 0 {
+		return math.Inf(1)
+	}
+	counts := columnCounts(letters, 0, 1, 0)
+	var chi float64
+	for n, c := range counts {
+		chi += chiSquared(c, freqFor(n))
+	}
+	return chi / float64(len(letters))
+}
+
+// keyLengthScore — оценки одной длины ключа.
+type keyLengthScore struct {
+	length  int
+	ic      float64
+	kasiski int // сколько расстояний Касиски делится на длину
+}
+
+// keyCandidate — восстановленный ключ с оценкой и расшифровкой.
+type keyCandidate struct {
+	key       string
+	score     float64
+	plaintext string
+}
+
+// estimateKeyLengths ранжирует длины ключа. Основной критерий — насколько индекс совпадений
+// столбцов не дотягивает до языкового (кратные истинной длины дают такой же IC, поэтому
+// длинные ключи слегка штрафуются); доля расстояний Касиски, кратных длине, даёт бонус.
+func estimateKeyLengths(letters []streamLetter) ([]keyLengthScore, []int) {
+	distances := kasiskiDistances(letters)
+	plainIC, randomIC := languageIC(letters)
+
+	limit := maxKeyLen
+	if half := len(letters) / 2; half < limit {
+		limit = half
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	scores := make([]keyLengthScore, 0, limit)
+	for l := 1; l <= limit; l++ {
+		s := keyLengthScore{length: l, ic: columnIC(letters, l)}
+		for _, d := range distances {
+			if d%l == 0 {
+				s.kasiski++
+			}
+		}
+		scores = append(scores, s)
+	}
+
+	rank := func(s keyLengthScore) float64 {
+		r := math.Max(0, (plainIC-s.ic)/(plainIC-randomIC)) + lengthPenalty*float64(s.length)
+		if len(distances) > 0 && s.length > 1 {
+			r -= kasiskiWeight * float64(s.kasiski) / float64(len(distances))
+		}
+		return r
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return rank(scores[i]) < rank(scores[j]) })
+	return scores, distances
+}
+
+// friedmanEstimate — классическая оценка длины ключа Фридмана по индексу совпадений всего текста.
+func friedmanEstimate(letters []streamLetter) float64 {
+	plainIC, randomIC := languageIC(letters)
+	ic := columnIC(letters, 1)
+	if ic <= randomIC {
+		return math.Inf(1)
+	}
+	return (plainIC - randomIC) / (ic - randomIC)
+}
+
+// breakReport — результат анализа шифртекста.
+type breakReport struct {
+	lengths    []keyLengthScore // длины ключа, лучшие первыми
+	repeats    int              // число повторов триграмм (Касиски)
+	friedman   float64          // оценка длины ключа Фридмана
+	plainIC    float64          // ожидаемый индекс совпадений открытого текста
+	candidates []keyCandidate   // ключи по возрастанию χ² расшифровки
+}
+
+// breakCipher восстанавливает ключ по одному шифртексту. Ключи строятся для лучших
+// длин (χ² по столбцам подгоняется независимо, поэтому сам по себе он всегда
+// «предпочёл» бы более длинный ключ), а выводятся по χ² всей расшифровки.
+// Анализ рассчитан на шифр Виженера с повторяющимся ключом над латиницей и
+// русским алфавитом, поэтому другие настройки s отклоняются.
+func breakCipher(ciphertext string, s settings) (*breakReport, error) {
+	if s.op != classic.OpVigenere || s.mode != classic.KeyRepeat || s.alphabets != nil {
+		return nil, fmt.Errorf("взлом поддерживает только шифр Виженера с повторяющимся ключом и стандартными алфавитами")
+	}
+	letters := lettersOf(ciphertext)
+	if len(letters) < 2 {
+		return nil, fmt.Errorf("слишком мало букв для анализа")
+	}
+
+	lengths, distances := estimateKeyLengths(letters)
+	plainIC, _ := languageIC(letters)
+	report := &breakReport{
+		lengths:  lengths,
+		repeats:  len(distances),
+		friedman: friedmanEstimate(letters),
+		plainIC:  plainIC,
+	}
+
+	candidates := keyCandidates(letters)
+	seen := make(map[string]bool)
+	for i := 0; i < len(lengths) && i < lengthsToTry; i++ {
+		l := lengths[i].length
+		key := make([]rune, l)
+		for col := 0; col < l; col++ {
+			key[col] = bestKeyRune(letters, col, l, candidates)
+		}
+		k := string(shortestPeriod(key))
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		plain, err := process(ciphertext, k, s, false)
+		if err != nil {
+			return nil, err
+		}
+		report.candidates = append(report.candidates, keyCandidate{key: k, score: textScore(plain), plaintext: plain})
+	}
+	// при равном χ² сохраняется порядок длин
+	sort.SliceStable(report.candidates, func(i, j int) bool {
+		return report.candidates[i].score < report.candidates[j].score
+	})
+	return report, nil
+}
+
+// printBreakReport выводит оценки длины ключа и ранжированных кандидатов.
+func printBreakReport(ciphertext string, s settings) {
+	report, err := breakCipher(ciphertext, s)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+
+	fmt.Println("\nОценка длины ключа:")
+	fmt.Printf("  Повторов триграмм (Касиски): %d\n", report.repeats)
+	if !math.IsInf(report.friedman, 1) {
+		fmt.Printf("  Оценка Фридмана:             %.1f\n", report.friedman)
+	}
+	fmt.Printf("  Ожидаемый IC текста:         %.4f\n", report.plainIC)
+	fmt.Println("  Длина   IC       Касиски")
+	for i := 0; i < len(report.lengths) && i < lengthsToTry; i++ {
+		s := report.lengths[i]
+		fmt.Printf("  %5d   %.4f   %d/%d\n", s.length, s.ic, s.kasiski, report.repeats)
+	}
+
+	fmt.Println("\nКандидаты ключа (χ² на букву — соответствие языку, меньше — лучше):")
+	for i, c := range report.candidates {
+		preview := []rune(c.plaintext)
+		if len(preview) > previewLength {
+			preview = append(preview[:previewLength], '…')
+		}
+		fmt.Printf("  %d. %-20q χ² = %.3f\n", i+1, c.key, c.score)
+		fmt.Printf("     %s\n", string(preview))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"classic"
+)
+
+// Открытые тексты для взлома: начала «Повести о двух городах» Диккенса
+// и «Анны Карениной» Толстого.
+const (
+	englishPassage = `It was the best of times, it was the worst of times, it was the age of wisdom,
+it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity,
+it was the season of Light, it was the season of Darkness, it was the spring of hope,
+it was the winter of despair, we had everything before us, we had nothing before us,
+we were all going direct to Heaven, we were all going direct the other way. In short,
+the period was so far like the present period, that some of its noisiest authorities
+insisted on its being received, for good or for evil, in the superlative degree of
+comparison only. There were a king with a large jaw and a queen with a plain face,
+on the throne of England; there were a king with a large jaw and a queen with a fair
+face, on the throne of France. In both countries it was clearer than crystal to the
+lords of the State preserves of loaves and fishes, that things in general were
+settled for ever.`
+
+	russianPassage = `Все счастливые семьи похожи друг на друга, каждая несчастливая семья несчастлива
+по-своему. Всё смешалось в доме Облонских. Жена узнала, что муж был в связи с бывшею
+в их доме француженкою-гувернанткой, и объявила мужу, что не может жить с ним в одном
+доме. Положение это продолжалось уже третий день и мучительно чувствовалось и самими
+супругами, и всеми членами семьи, и домочадцами. Все члены семьи и домочадцы
+чувствовали, что нет смысла в их сожительстве и что на каждом постоялом дворе
+случайно сошедшиеся люди более связаны между собой, чем они, члены семьи и домочадцы
+Облонских. Жена не выходила из своих комнат, мужа третий день не было дома. Дети
+бегали по всему дому, как потерянные; англичанка поссорилась с экономкой и написала
+записку приятельнице, прося приискать ей новое место; повар ушёл ещё вчера со двора,
+во время самого обеда; чёрная кухарка и кучер просили расчёта.`
+)
+
+// breakCases — тексты и ключи, которыми они шифруются.
+var breakCases = []struct {
+	name, text, key string
+}{
+	{"английский", englishPassage, "lemon"},
+	{"русский", russianPassage, "ключшифра"},
+}
+
+// encryptCase шифрует текст шифром Виженера с настройками по умолчанию.
+func encryptCase(t *testing.T, text, key string) []streamLetter {
+	t.Helper()
+	ct, err := process(text, key, settings{}, true)
+	if err != nil {
+		t.Fatalf("шифрование: %v", err)
+	}
+	return lettersOf(ct)
+}
+
+// TestKasiski проверяет, что большинство расстояний между повторами триграмм
+// кратно длине ключа.
+func TestKasiski(t *testing.T) {
+	for _, c := range breakCases {
+		keyLen := len([]rune(c.key))
+		distances := kasiskiDistances(encryptCase(t, c.text, c.key))
+		if len(distances) == 0 {
+			t.Errorf("%s: повторы триграмм не найдены", c.name)
+			continue
+		}
+		multiples := 0
+		for _, d := range distances {
+			if d%keyLen == 0 {
+				multiples++
+			}
+		}
+		if 2*multiples < len(distances) {
+			t.Errorf("%s: кратны %d лишь %d из %d расстояний", c.name, keyLen, multiples, len(distances))
+		}
+	}
+}
+
+// TestFriedman проверяет оценку Фридмана: для открытого текста она близка к 1,
+// для шифртекста — не меньше половины длины ключа. При длинном ключе IC
+// шифртекста почти случаен и оценка сильно завышается, поэтому сверху не ограничена.
+func TestFriedman(t *testing.T) {
+	for _, c := range breakCases {
+		if got := friedmanEstimate(lettersOf(c.text)); math.Abs(got-1) > 0.5 {
+			t.Errorf("%s: оценка Фридмана открытого текста %.2f, ожидается около 1", c.name, got)
+		}
+		keyLen := float64(len([]rune(c.key)))
+		if got := friedmanEstimate(encryptCase(t, c.text, c.key)); got < keyLen/2 {
+			t.Errorf("%s: оценка Фридмана %.2f, ожидается не меньше %.1f", c.name, got, keyLen/2)
+		}
+	}
+}
+
+// TestColumnIC проверяет, что при верной длине ключа индекс совпадений столбцов
+// близок к языковому, а при длине 1 — к случайному тексту.
+func TestColumnIC(t *testing.T) {
+	for _, c := range breakCases {
+		letters := encryptCase(t, c.text, c.key)
+		plain, random := languageIC(letters)
+		mid := (plain + random) / 2
+		if ic := columnIC(letters, len([]rune(c.key))); ic < mid {
+			t.Errorf("%s: IC столбцов при верной длине %.4f, ожидается около %.4f", c.name, ic, plain)
+		}
+		if ic := columnIC(letters, 1); ic > mid {
+			t.Errorf("%s: IC шифртекста %.4f, ожидается около %.4f", c.name, ic, random)
+		}
+	}
+}
+
+// TestBreakCipher проверяет, что взлом ставит первой верную длину ключа
+// и восстанавливает сам ключ по χ².
+func TestBreakCipher(t *testing.T) {
+	for _, c := range breakCases {
+		ct, err := process(c.text, c.key, settings{}, true)
+		if err != nil {
+			t.Fatalf("%s: шифрование: %v", c.name, err)
+		}
+		report, err := breakCipher(ct, settings{})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got, want := report.lengths[0].length, len([]rune(c.key)); got != want {
+			t.Errorf("%s: длина ключа %d, ожидается %d", c.name, got, want)
+		}
+		if len(report.candidates) == 0 {
+			t.Fatalf("%s: нет кандидатов ключа", c.name)
+		}
+		if best := report.candidates[0]; best.key != c.key || best.plaintext != c.text {
+			t.Errorf("%s: ключ %q, ожидается %q", c.name, best.key, c.key)
+		}
+	}
+}
+
+// TestBreakCipherSettings проверяет, что взлом отклоняет шифры, под которые
+// не рассчитан его анализ.
+func TestBreakCipherSettings(t *testing.T) {
+	ct, err := process(englishPassage, "lemon", settings{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := classic.ParseAlphabet("digits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []settings{
+		{op: classic.OpBeaufort},
+		{mode: classic.KeyAuto},
+		{alphabets: classic.Alphabets{digits}},
+	} {
+		if _, err := breakCipher(ct, s); err == nil {
+			t.Errorf("настройки %+v: ожидается ошибка", s)
+		}
+	}
+}
@@ -1,127 +1,182 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
-	"unicode"
-)
 
-const (
-	latinAlphabet    = "abcdefghijklmnopqrstuvwxyz"
-	cyrillicAlphabet = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"
+	"classic"
 )
 
-// alphabetFor возвращает срез рун алфавита для данного символа (или nil).
-func alphabetFor(r rune) []rune {
-	lr := unicode.ToLower(r)
-	if lr >= 'a' && lr <= 'z' {
-		return []rune(latinAlphabet)
-	}
-	for _, c := range []rune(cyrillicAlphabet) {
-		if c == lr {
-			return []rune(cyrillicAlphabet)
-		}
-	}
-	return nil
+// settings — параметры шифра, выбранные в меню или флагами.
+type settings struct {
+	op        classic.ShiftOp
+	mode      classic.KeyMode
+	alphabets classic.Alphabets // nil — латиница и русский алфавит
 }
 
-// shiftRune сдвигает руну на сдвиг, заданный символом ключа.
-func shiftRune(r rune, keyRune rune, encrypt bool) rune {
-	alpha := alphabetFor(r)
-	if alpha == nil {
-		return r // не буква — оставляем как есть
+// newCipher создаёт шифр по ключу; для бегущего ключа key — текст книги.
+func (s settings) newCipher(key string) (*classic.Vigenere, error) {
+	return classic.NewShiftCipher(classic.VigenereKey(key), s.op, s.mode, s.alphabets)
+}
+
+// process шифрует или дешифрует текст шифром семейства Виженера.
+func process(text, key string, s settings, encrypt bool) (string, error) {
+	c, err := s.newCipher(key)
+	if err != nil {
+		return "", err
 	}
+	return classic.Process(c, text, encrypt)
+}
+
+// keyModes — режимы ключа в порядке меню и их имена для флага -keymode.
+var keyModes = []struct {
+	mode classic.KeyMode
+	name string
+}{
+	{classic.KeyRepeat, "repeat"},
+	{classic.KeyAuto, "auto"},
+	{classic.KeyRunning, "running"},
+}
 
-	alphaLen := len(alpha)
-	lr := unicode.ToLower(r)
+// shiftOps — правила сдвига в порядке меню и их имена для флага -op.
+var shiftOps = []struct {
+	op   classic.ShiftOp
+	name string
+}{
+	{classic.OpVigenere, "vigenere"},
+	{classic.OpBeaufort, "beaufort"},
+	{classic.OpVariantBeaufort, "variant"},
+	{classic.OpGronsfeld, "gronsfeld"},
+}
 
-	pos := -1
-	for i, c := range alpha {
-		if c == lr {
-			pos = i
-			break
+// runFlags обрабатывает вход без меню (флаги -encrypt/-decrypt).
+// Для бегущего ключа -key — путь к файлу книги; tracePath — файл трассировки.
+func runFlags(sf *classic.StreamFlags, opName, modeName, tracePath string, alphabets classic.Alphabets) error {
+	s := settings{op: -1, mode: -1, alphabets: alphabets}
+	for _, o := range shiftOps {
+		if o.name == opName {
+			s.op = o.op
 		}
 	}
-	keyAlpha := alphabetFor(keyRune)
-	if keyAlpha == nil {
-		return r
+	if s.op < 0 {
+		return fmt.Errorf("неизвестный шифр %q", opName)
 	}
-	lk := unicode.ToLower(keyRune)
-	keyPos := -1
-	for i, c := range keyAlpha {
-		if c == lk {
-			keyPos = i
-			break
+	for _, m := range keyModes {
+		if m.name == modeName {
+			s.mode = m.mode
 		}
 	}
-	if pos < 0 || keyPos < 0 {
-		return r
+	if s.mode < 0 {
+		return fmt.Errorf("неизвестный режим ключа %q", modeName)
 	}
-	// применяем шифр Виженера: сложение/вычитание позиций по модулю N
-	var newPos int
-	if encrypt {
-		newPos = (pos + keyPos) % alphaLen
-	} else {
-		newPos = (pos - keyPos + alphaLen) % alphaLen
+	key := sf.Key
+	if s.mode == classic.KeyRunning {
+		book, err := os.ReadFile(key)
+		if err != nil {
+			return fmt.Errorf("не удалось прочитать книгу: %w", err)
+		}
+		key = string(book)
 	}
-
-	result := alpha[newPos]
-	if unicode.IsUpper(r) {
-		result = unicode.ToUpper(result)
+	c, err := s.newCipher(key)
+	if err != nil {
+		return err
 	}
-	return result
-}
-
-// process шифрует или дешифрует текст методом Виженера.
-func process(text, key string, encrypt bool) (string, error) {
-	if key == "" {
-		return "", fmt.Errorf("ключ не может быть пустым")
+	if tracePath == "" {
+		return sf.Run(c)
 	}
-
-	keyRunes := []rune(key)
-	var filteredKey []rune
-	for _, r := range keyRunes {
-		if alphabetFor(r) != nil {
-			filteredKey = append(filteredKey, r)
-		}
+	done, err := classic.TraceFile(c, tracePath)
+	if err != nil {
+		return err
 	}
-	if len(filteredKey) == 0 {
-		return "", fmt.Errorf("ключ должен содержать хотя бы одну букву")
+	if err := sf.Run(c); err != nil {
+		done()
+		return err
 	}
+	return done()
+}
 
-	var sb strings.Builder
-	keyIndex := 0
-	for _, r := range []rune(text) {
-		if alphabetFor(r) != nil {
-			keyRune := filteredKey[keyIndex%len(filteredKey)]
-			sb.WriteRune(shiftRune(r, keyRune, encrypt))
-			keyIndex++
-		} else {
-			sb.WriteRune(r)
-		}
+// chooseOp предлагает выбрать правило сдвига.
+func chooseOp(current classic.ShiftOp) classic.ShiftOp {
+	fmt.Println("Шифр:")
+	for i, o := range shiftOps {
+		fmt.Printf("  %d — %s\n", i+1, o.op)
 	}
-	return sb.String(), nil
+	n, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine(": ")))
+	if err != nil || n < 1 || n > len(shiftOps) {
+		fmt.Println("Неверный выбор, шифр не изменён.")
+		return current
+	}
+	return shiftOps[n-1].op
 }
 
-var stdinScanner = bufio.NewScanner(os.Stdin)
+// chooseKeyMode предлагает выбрать способ получения ключевого потока.
+func chooseKeyMode(current classic.KeyMode) classic.KeyMode {
+	fmt.Println("Режим ключа:")
+	for i, m := range keyModes {
+		fmt.Printf("  %d — %s\n", i+1, m.mode)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(classic.ReadLine(": ")))
+	if err != nil || n < 1 || n > len(keyModes) {
+		fmt.Println("Неверный выбор, режим не изменён.")
+		return current
+	}
+	return keyModes[n-1].mode
+}
 
-func readLine(prompt string) string {
-	fmt.Print(prompt)
-	stdinScanner.Scan()
-	return stdinScanner.Text()
+// readKey запрашивает ключ; для бегущего ключа — читает текст книги из файла.
+func readKey(mode classic.KeyMode) (string, error) {
+	if mode != classic.KeyRunning {
+		return strings.TrimSpace(classic.ReadLine("Введите ключ:  ")), nil
+	}
+	path := strings.TrimSpace(classic.ReadLine("Файл книги:    "))
+	book, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось прочитать книгу: %w", err)
+	}
+	return string(book), nil
 }
 
 func main() {
+	loadAlphabets := classic.AlphabetFlags(flag.CommandLine)
+	sf := classic.RegisterStreamFlags(flag.CommandLine)
+	opName := flag.String("op", "vigenere", "шифр без меню: vigenere, beaufort, variant, gronsfeld")
+	modeName := flag.String("keymode", "repeat", "режим ключа без меню: repeat, auto, running (-key — файл книги)")
+	tracePath := flag.String("trace", "", "файл пошаговой трассировки (.csv, .json, иначе таблица; - — на экран)")
+	flag.Parse()
+	alphabets, err := loadAlphabets()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+	if sf.Active() {
+		if err := runFlags(sf, *opName, *modeName, *tracePath, alphabets); err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Println()
+	if alphabets != nil {
+		fmt.Println("Алфавиты:", alphabets)
+	}
 
+	s := settings{alphabets: alphabets}
 	for {
+		fmt.Println("Шифр:", s.op, "| режим ключа:", s.mode)
 		fmt.Println("Выберите действие:")
 		fmt.Println("  1 — Зашифровать")
 		fmt.Println("  2 — Расшифровать")
+		fmt.Println("  3 — Взломать (только шифртекст)")
+		fmt.Println("  4 — Режим ключа")
+		fmt.Println("  5 — Обработать файл")
+		fmt.Println("  6 — Выбрать шифр (Виженер, Бофор, Гронсфельд)")
+		fmt.Println("  7 — Пошаговая трассировка")
 		fmt.Println("  0 — Выход")
-		choice := strings.TrimSpace(readLine(": "))
+		choice := strings.TrimSpace(classic.ReadLine(": "))
 
 		switch choice {
 		case "1", "2":
@@ -132,10 +187,14 @@ func main() {
 			}
 			_ = action
 
-			text := readLine("Введите текст: ")
-			key := strings.TrimSpace(readLine("Введите ключ:  "))
+			text := classic.ReadLine("Введите текст: ")
+			key, err := readKey(s.mode)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
 
-			result, err := process(text, key, encrypt)
+			result, err := process(text, key, s, encrypt)
 			if err != nil {
 				fmt.Println("Ошибка:", err)
 				continue
@@ -148,6 +207,61 @@ func main() {
 			}
 			fmt.Println()
 
+		case "3":
+			text := classic.ReadLine("Введите шифртекст: ")
+			printBreakReport(text, s)
+
+		case "4":
+			s.mode = chooseKeyMode(s.mode)
+			fmt.Println()
+
+		case "5":
+			job, err := classic.ReadFileJob()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			key, err := readKey(s.mode)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			c, err := s.newCipher(key)
+			if err == nil {
+				err = job.Run(c)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+
+		case "6":
+			s.op = chooseOp(s.op)
+			fmt.Println()
+
+		case "7":
+			var encrypt bool
+			switch strings.TrimSpace(classic.ReadLine("1 — зашифровать, 2 — расшифровать: ")) {
+			case "1":
+				encrypt = true
+			case "2":
+			default:
+				fmt.Println("Неверный выбор, попробуйте снова.")
+				continue
+			}
+			text := classic.ReadLine("Введите текст: ")
+			key, err := readKey(s.mode)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			c, err := s.newCipher(key)
+			if err == nil {
+				err = classic.RunTrace(c, text, encrypt)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+
 		case "0":
 			fmt.Println("Выход.")
 			return
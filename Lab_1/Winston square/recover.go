@@ -0,0 +1,541 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"classic"
+)
+
+// Восстановление таблиц двойного квадрата по известной паре «открытый текст —
+// шифртекст». Каждая биграмма связывает координаты четырёх букв: первая буква
+// открытого текста и одна из букв результата лежат в одной строке, вторая
+// буква открытого текста и другая буква результата — тоже, а столбцы букв
+// результата берутся у букв открытого текста крест-накрест. Равенства строк
+// и столбцов объединяются в классы; шифр не меняется при одновременной
+// перестановке строк (столбцов) обеих таблиц, поэтому классы расставляются
+// так, чтобы конец таблиц был похож на остаток алфавита, как в BuildTable.
+
+// Таблицы биграммы: левая (верхняя, ключ 1) и правая (нижняя, ключ 2).
+const (
+	tableL = 0
+	tableR = 1
+)
+
+// coordVar — строка или столбец буквы в одной из таблиц.
+type coordVar struct {
+	table  int
+	letter rune
+	col    bool
+}
+
+// unionFind объединяет координаты, которые по шифртексту обязаны совпадать.
+type unionFind map[coordVar]coordVar
+
+func (u unionFind) find(v coordVar) coordVar {
+	p, ok := u[v]
+	if !ok {
+		u[v] = v
+		return v
+	}
+	if p == v {
+		return v
+	}
+	root := u.find(p)
+	u[v] = root
+	return root
+}
+
+func (u unionFind) union(a, b coordVar) {
+	ra, rb := u.find(a), u.find(b)
+	if ra != rb {
+		u[ra] = rb
+	}
+}
+
+// outTables возвращает таблицы, в которых при шифровании лежат первая и вторая
+// буквы результата (до перестановки букв в транспонированном варианте).
+func outTables(v classic.Variant) (int, int) {
+	if v.Layout == classic.LayoutHorizontal {
+		return tableR, tableL
+	}
+	return tableL, tableR
+}
+
+// recovered — восстановленные таблицы одного алфавита.
+type recovered struct {
+	spec     classic.Square
+	tables   [2]*classic.Table // неизвестные ячейки — 0
+	keywords [2]string         // начало таблицы до упорядоченного остатка алфавита
+	unplaced [2][]rune         // буквы, для которых не нашлось строки или столбца
+	bigrams  int
+}
+
+// streamLetters возвращает нормализованные буквы потока i таблиц squares.
+func streamLetters(text string, squares classic.Squares, i int) []rune {
+	lat, cyr := classic.Streams(squares.Tokenize(text))
+	letters := [2][]rune{lat, cyr}[i]
+	out := make([]rune, len(letters))
+	for j, r := range letters {
+		out[j] = squares[i].Norm(r)
+	}
+	return out
+}
+
+// recoverTables восстанавливает пару таблиц по открытому тексту plain и
+// шифртексту cipher одного алфавита (буквы уже нормализованы).
+func recoverTables(plain, cipher []rune, spec classic.Square, v classic.Variant) (*recovered, error) {
+	if len(plain)%2 != 0 {
+		plain = append(plain, spec.Filler) // заполнитель, добавленный при шифровании
+	}
+	if len(plain) != len(cipher) {
+		return nil, fmt.Errorf("%s: в открытом тексте %d букв (с заполнителем), в шифртексте — %d", spec, len(plain), len(cipher))
+	}
+
+	out1, out2 := outTables(v)
+	u := make(unionFind)
+	for i := 0; i < len(plain); i += 2 {
+		a, b := plain[i], plain[i+1]
+		c1, c2 := cipher[i], cipher[i+1]
+		if v.Transposed {
+			c1, c2 = c2, c1
+		}
+		u.union(coordVar{tableL, a, false}, coordVar{out1, c1, false})
+		u.union(coordVar{tableR, b, true}, coordVar{out1, c1, true})
+		u.union(coordVar{tableR, b, false}, coordVar{out2, c2, false})
+		u.union(coordVar{tableL, a, true}, coordVar{out2, c2, true})
+	}
+
+	// Разные буквы одной таблицы не могут делить ячейку.
+	type cell struct {
+		table    int
+		row, col coordVar
+	}
+	cells := make(map[cell]rune)
+	var letters [2][]rune
+	for _, t := range []int{tableL, tableR} {
+		for _, r := range spec.Letters {
+			row := coordVar{t, r, false}
+			if _, ok := u[row]; !ok {
+				continue
+			}
+			c := cell{t, u.find(row), u.find(coordVar{t, r, true})}
+			if prev, ok := cells[c]; ok {
+				return nil, fmt.Errorf("%s: буквы %c и %c %s таблицы попадают в одну ячейку — текст не соответствует варианту шифра",
+					spec, prev, r, tableName(t))
+			}
+			cells[c] = r
+			letters[t] = append(letters[t], r)
+		}
+	}
+
+	rec := &recovered{spec: spec, bigrams: len(plain) / 2}
+	rec.arrange(u, letters, v.Layout == classic.LayoutVertical)
+	return rec, nil
+}
+
+func tableName(t int) string {
+	if t == tableL {
+		return "левой (верхней)"
+	}
+	return "правой (нижней)"
+}
+
+// largestClasses возвращает не более limit самых больших классов строк или
+// столбцов, в которых есть буквы таблиц tables. Если классов больше, чем строк
+// таблицы, часть из них на самом деле совпадает, но какие именно — по тексту
+// не определить.
+func largestClasses(u unionFind, letters [2][]rune, tables []int, col bool, limit int) []coordVar {
+	size := make(map[coordVar]int)
+	var order []coordVar
+	for _, t := range tables {
+		for _, r := range letters[t] {
+			root := u.find(coordVar{t, r, col})
+			if size[root] == 0 {
+				order = append(order, root)
+			}
+			size[root]++
+		}
+	}
+	slices.SortStableFunc(order, func(a, b coordVar) int { return cmp.Compare(size[b], size[a]) })
+	if len(order) > limit {
+		order = order[:limit]
+	}
+	return order
+}
+
+// placedLetter — буква таблицы и номера её классов по общей и собственной осям.
+type placedLetter struct {
+	r           rune
+	shared, own int
+}
+
+// arrange расставляет классы по строкам и столбцам. Таблицы рядом делят
+// строки, таблицы друг над другом — столбцы (sharedCols); по второй оси
+// у каждой таблицы свои классы. Ищется назначение, при котором конец обеих
+// таблиц длиннее всего совпадает с остатком алфавита. Перебор всех
+// перестановок обеих осей слишком велик (для 6×6 — 720·2·720 расстановок),
+// поэтому перебирается только общая ось, классы своей оси упорядочиваются
+// жадно, по номерам букв в алфавите, а затем этот порядок улучшается.
+func (rec *recovered) arrange(u unionFind, letters [2][]rune, sharedCols bool) {
+	spec := rec.spec
+	nShared, nOwn := spec.Rows, spec.Cols
+	if sharedCols {
+		nShared, nOwn = spec.Cols, spec.Rows
+	}
+	shared := largestClasses(u, letters, []int{tableL, tableR}, sharedCols, nShared)
+	var placed [2][]placedLetter
+	for t, ls := range letters {
+		own := largestClasses(u, letters, []int{t}, !sharedCols, nOwn)
+		for _, r := range ls {
+			si := slices.Index(shared, u.find(coordVar{t, r, sharedCols}))
+			oi := slices.Index(own, u.find(coordVar{t, r, !sharedCols}))
+			if si < 0 || oi < 0 {
+				rec.unplaced[t] = append(rec.unplaced[t], r)
+				continue
+			}
+			placed[t] = append(placed[t], placedLetter{r, si, oi})
+		}
+	}
+
+	alpha := []rune(spec.Letters)
+	index := make(map[rune]int, len(alpha))
+	for i, r := range alpha {
+		index[r] = i
+	}
+	n := spec.Rows * spec.Cols
+	grid := make([]rune, n)
+	fill := func(t int, sharedPerm, ownPerm []int) {
+		clear(grid)
+		for _, p := range placed[t] {
+			i, j := sharedPerm[p.shared], ownPerm[p.own]
+			if sharedCols {
+				i, j = j, i
+			}
+			grid[i*spec.Cols+j] = p.r
+		}
+	}
+	tableScore := func(t int, sharedPerm, ownPerm []int) int {
+		fill(t, sharedPerm, ownPerm)
+		return n - tailStart(grid, index, len(alpha))
+	}
+
+	// В остатке алфавита номер буквы растёт на 1 с каждым столбцом и на Cols
+	// с каждой строкой. Для порядка классов своей оси при известном порядке
+	// общей из номера буквы вычитается сдвиг её места по общей оси; берётся
+	// буква с последнего места — она вероятнее всего лежит в остатке.
+	offset := func(slot int) int {
+		if sharedCols {
+			return slot
+		}
+		return slot * spec.Cols
+	}
+	ownOrder := func(t int, sharedPerm []int) []int {
+		key, slot := make([]int, nOwn), make([]int, nOwn)
+		for i := range key {
+			// о пустых классах ничего не известно; чаще всего это редкие
+			// буквы конца алфавита, поэтому они ставятся в конец
+			key[i], slot[i] = math.MaxInt, -1
+		}
+		for _, p := range placed[t] {
+			if s := sharedPerm[p.shared]; s > slot[p.own] {
+				slot[p.own], key[p.own] = s, index[p.r]-offset(s)
+			}
+		}
+		return rankBy(key)
+	}
+
+	// Общая ось перебирается полностью, своя для каждой перестановки
+	// упорядочивается жадно; для лучшей общей оси порядок своей улучшается.
+	bestScore := -1
+	var sharedPerm []int
+	permutations(nShared, func(p []int) {
+		score := 0
+		for t := range placed {
+			score += tableScore(t, p, ownOrder(t, p))
+		}
+		if score > bestScore {
+			bestScore, sharedPerm = score, slices.Clone(p)
+		}
+	})
+	bestOwn := func(t int) []int {
+		ownPerm := ownOrder(t, sharedPerm)
+		improve(ownPerm, func(p []int) int { return tableScore(t, sharedPerm, p) })
+		return ownPerm
+	}
+
+	for t := range placed {
+		ownPerm := bestOwn(t)
+		fill(t, sharedPerm, ownPerm)
+		k := tailStart(grid, index, len(alpha))
+		fillTail(grid, k, alpha, index)
+		rec.keywords[t] = keywordOf(grid[:k])
+		rec.tables[t] = gridTable(grid, spec.Rows, spec.Cols)
+		rec.unplaced[t] = slices.DeleteFunc(rec.unplaced[t], func(r rune) bool {
+			_, ok := rec.tables[t].Pos[r]
+			return ok
+		})
+	}
+}
+
+// permutations вызывает f для каждой перестановки чисел 0..n−1 (алгоритм Хипа).
+func permutations(n int, f func([]int)) {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	c := make([]int, n)
+	f(p)
+	for i := 0; i < n; {
+		if c[i] < i {
+			if i%2 == 0 {
+				p[0], p[i] = p[i], p[0]
+			} else {
+				p[c[i]], p[i] = p[i], p[c[i]]
+			}
+			f(p)
+			c[i]++
+			i = 0
+		} else {
+			c[i] = 0
+			i++
+		}
+	}
+}
+
+// rankBy возвращает перестановку, ставящую классы по возрастанию key:
+// perm[i] — место класса i. При равных ключах сохраняется исходный порядок.
+func rankBy(key []int) []int {
+	order := make([]int, len(key))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(key[a], key[b]) })
+	perm := make([]int, len(key))
+	for place, i := range order {
+		perm[i] = place
+	}
+	return perm
+}
+
+// improve улучшает перестановку perm (perm[i] — место класса i), перенося
+// по одному классу на другое место со сдвигом остальных, пока оценка score
+// растёт, и возвращает достигнутую оценку. Перенос, а не обмен пар, нужен,
+// чтобы строку ключа, оказавшуюся среди строк остатка, можно было сразу
+// поставить в начало: промежуточные обмены оценку не повышают.
+func improve(perm []int, score func([]int) int) int {
+	order := make([]int, len(perm))
+	for i, place := range perm {
+		order[place] = i
+	}
+	apply := func(order []int) {
+		for place, i := range order {
+			perm[i] = place
+		}
+	}
+	best := score(perm)
+	for improved := true; improved; {
+		improved = false
+		for from := range order {
+			for to := range order {
+				if from == to {
+					continue
+				}
+				moved := slices.Insert(slices.Delete(slices.Clone(order), from, from+1), to, order[from])
+				apply(moved)
+				if s := score(perm); s > best {
+					best, order, improved = s, moved, true
+				}
+			}
+		}
+	}
+	apply(order)
+	return best
+}
+
+// tailStart находит начало остатка алфавита в таблице, прочитанной построчно:
+// известные буквы после него идут по алфавиту, и между соседними хватает букв
+// алфавита, чтобы заполнить промежуточные ячейки.
+func tailStart(grid []rune, index map[rune]int, alphaLen int) int {
+	next, nextPos := alphaLen, len(grid) // воображаемая буква за концом алфавита
+	start := 0
+	for p := len(grid) - 1; p >= 0; p-- {
+		r := grid[p]
+		if r == 0 {
+			continue
+		}
+		i := index[r]
+		if i >= next || next-i < nextPos-p {
+			start = p + 1
+			break
+		}
+		next, nextPos = i, p
+	}
+	if nextPos == len(grid) {
+		return len(grid) // известных букв в остатке нет
+	}
+	// перед первой известной буквой остатка — только меньшие буквы алфавита
+	return max(start, nextPos-next)
+}
+
+// fillTail дописывает в остаток алфавита буквы там, где промежуток между
+// известными буквами однозначен: букв алфавита между ними, которых ещё нет
+// в таблице, ровно столько, сколько пустых ячеек.
+func fillTail(grid []rune, k int, alpha []rune, index map[rune]int) {
+	used := make(map[rune]bool)
+	for _, r := range grid {
+		if r != 0 {
+			used[r] = true
+		}
+	}
+	prev, prevPos := -1, k-1 // воображаемая буква перед началом алфавита
+	for p := k; p <= len(grid); p++ {
+		cur := len(alpha)
+		if p < len(grid) {
+			if grid[p] == 0 {
+				continue
+			}
+			cur = index[grid[p]]
+		}
+		var gap []rune
+		for _, r := range alpha[prev+1 : cur] {
+			if !used[r] {
+				gap = append(gap, r)
+			}
+		}
+		if len(gap) == p-prevPos-1 {
+			for i, r := range gap {
+				grid[prevPos+1+i] = r
+				used[r] = true
+			}
+		}
+		prev, prevPos = cur, p
+	}
+}
+
+// keywordOf записывает начало таблицы; неизвестные буквы — «?».
+func keywordOf(prefix []rune) string {
+	var sb strings.Builder
+	for _, r := range prefix {
+		if r == 0 {
+			r = '?'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// gridTable превращает построчную запись в таблицу; пустые ячейки остаются 0.
+func gridTable(grid []rune, rows, cols int) *classic.Table {
+	t := &classic.Table{Grid: make([][]rune, rows), Rows: rows, Cols: cols, Pos: make(map[rune][2]int)}
+	for i := range t.Grid {
+		t.Grid[i] = make([]rune, cols)
+		for j := range t.Grid[i] {
+			if r := grid[i*cols+j]; r != 0 {
+				t.Set(i, j, r)
+			}
+		}
+	}
+	return t
+}
+
+// decrypt расшифровывает поток букв восстановленными таблицами; буквы, ячейки
+// которых не восстановлены, заменяются на «?».
+func (rec *recovered) decrypt(letters []rune, v classic.Variant) []rune {
+	out1, out2 := outTables(v)
+	t1, t2 := rec.tables[out1], rec.tables[out2]
+	left, right := rec.tables[tableL], rec.tables[tableR]
+	result := make([]rune, 0, len(letters)+1)
+	for i := 0; i < len(letters); i += 2 {
+		if i+1 == len(letters) {
+			result = append(result, '?') // нечётное число букв — шифртекст неполон
+			break
+		}
+		c1, c2 := letters[i], letters[i+1]
+		if v.Transposed {
+			c1, c2 = c2, c1
+		}
+		a, b := '?', '?'
+		p1, ok1 := t1.Pos[c1]
+		p2, ok2 := t2.Pos[c2]
+		if ok1 && ok2 {
+			if r := left.Grid[p1[0]][p2[1]]; r != 0 {
+				a = r
+			}
+			if r := right.Grid[p2[0]][p1[1]]; r != 0 {
+				b = r
+			}
+		}
+		result = append(result, a, b)
+	}
+	return result
+}
+
+// known возвращает число восстановленных ячеек таблицы.
+func known(t *classic.Table) int { return len(t.Pos) }
+
+// runRecover — диалог восстановления таблиц по известному открытому тексту.
+func runRecover(v classic.Variant, squares classic.Squares) {
+	fmt.Println("Вариант:", v)
+	plain := classic.ReadLine("Открытый текст: ")
+	cipher := classic.ReadLine("Шифртекст:      ")
+
+	var recs [2]*recovered
+	for i, spec := range squares {
+		p := streamLetters(plain, squares, i)
+		c := streamLetters(cipher, squares, i)
+		if len(p) == 0 && len(c) == 0 {
+			continue
+		}
+		rec, err := recoverTables(p, c, spec, v)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			return
+		}
+		recs[i] = rec
+
+		n := spec.Rows * spec.Cols
+		fmt.Printf("\n%s, %d биграмм: восстановлено ячеек %d/%d (ключ 1) и %d/%d (ключ 2)\n",
+			spec, rec.bigrams, known(rec.tables[tableL]), n, known(rec.tables[tableR]), n)
+		fmt.Println("Таблицы определены с точностью до одновременной перестановки строк и столбцов.")
+		if known(rec.tables[tableL]) < n || known(rec.tables[tableR]) < n {
+			fmt.Println("Данных недостаточно: часть строк и столбцов могла не объединиться, расположение предположительное.")
+		}
+		printPair(rec.tables[tableL], rec.tables[tableR], v.Layout)
+		for t, kw := range rec.keywords {
+			fmt.Printf("  Ключ %d (начало таблицы до остатка алфавита): %s\n", t+1, kw)
+			if len(rec.unplaced[t]) > 0 {
+				fmt.Printf("    Без определённой строки или столбца: %s\n", string(rec.unplaced[t]))
+			}
+		}
+	}
+	if recs[0] == nil && recs[1] == nil {
+		fmt.Println("Ошибка: в тексте нет букв")
+		return
+	}
+	fmt.Println()
+
+	for {
+		text := classic.ReadLine("Шифртекст для расшифровки (пусто — назад): ")
+		if strings.TrimSpace(text) == "" {
+			fmt.Println()
+			return
+		}
+		var results [2][]rune
+		for i := range squares {
+			letters := streamLetters(text, squares, i)
+			if len(letters) == 0 {
+				continue
+			}
+			if recs[i] == nil {
+				results[i] = []rune(strings.Repeat("?", len(letters)))
+				continue
+			}
+			results[i] = recs[i].decrypt(letters, v)
+		}
+		fmt.Println("Расшифровка:", classic.Restore(squares.Tokenize(text), results[0], results[1]))
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"classic"
+)
+
+// Известная пара: английский и русский тексты по несколько сотен букв,
+// зашифрованные таблицами BuildTable с ключами keyL и keyR.
+const (
+	recoverPlain = "It was the best of times, it was the worst of times, it was the age of wisdom, " +
+		"it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity, " +
+		"it was the season of Light, it was the season of Darkness, it was the spring of hope, " +
+		"it was the winter of despair, we had everything before us, we had nothing before us, " +
+		"we were all going direct to Heaven, we were all going direct the other way. " +
+		"Quickly, the lazy jury vexed a few bad zombies; six big jets fly over the quiz hut, " +
+		"and the wizard quickly jinxed the gnomes before they vaporized in 1775, 2648 and 1903. " +
+		"Все счастливые семьи похожи друг на друга, каждая несчастливая семья несчастлива по-своему. " +
+		"Всё смешалось в доме Облонских. Жена узнала, что муж был в связи с бывшею в их доме " +
+		"француженкою-гувернанткой, и объявила мужу, что не может жить с ним в одном доме. " +
+		"Положение это продолжалось уже третий день и мучительно чувствовалось и самими супругами, " +
+		"и всеми членами семьи, и домашними. Съешь же ещё этих мягких французских булок да выпей чаю. " +
+		"Широкая электрификация южных губерний даст мощный толчок подъёму сельского хозяйства; " +
+		"в чащах юга жил бы цитрус — да, но фальшивый экземпляр!"
+	recoverKeyL = "keyword ключевое"
+	recoverKeyR = "monarchy шифровщик"
+)
+
+// wantKeyword — ключ без повторов и букв вне таблицы: именно он стоит
+// в начале таблицы BuildTable перед остатком алфавита.
+func wantKeyword(key string, spec classic.Square) string {
+	seen := make(map[rune]bool)
+	var out []rune
+	for _, r := range key {
+		r = spec.Norm(r)
+		if spec.Has(r) && !seen[r] {
+			seen[r] = true
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// TestRecoverKeywords проверяет, что по известной паре восстанавливаются
+// таблицы BuildTable и ключевые слова обеих таблиц во всех вариантах шифра,
+// в том числе для таблиц 6×6 и 5×6.
+func TestRecoverKeywords(t *testing.T) {
+	var big classic.Squares
+	for i, name := range []string{"latin6x6", "cyrillic5x6"} {
+		sq, err := classic.ParseSquare(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		big[i] = sq
+	}
+	for _, squares := range []classic.Squares{classic.DefaultSquares, big} {
+		testRecoverKeywords(t, squares)
+	}
+}
+
+func testRecoverKeywords(t *testing.T, squares classic.Squares) {
+	t.Helper()
+	for _, layout := range []classic.Layout{classic.LayoutHorizontal, classic.LayoutVertical} {
+		for _, transposed := range []bool{false, true} {
+			v := classic.Variant{Layout: layout, Transposed: transposed}
+			cipher, err := process(recoverPlain, recoverKeyL, recoverKeyR, v, squares, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := classic.NewTwoSquare(classic.TwoSquareKey{Left: recoverKeyL, Right: recoverKeyR}, v, squares)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := [2][2]*classic.Table{{c.LatL, c.LatR}, {c.CyrL, c.CyrR}}
+
+			for i, spec := range squares {
+				rec, err := recoverTables(streamLetters(recoverPlain, squares, i), streamLetters(cipher, squares, i), spec, v)
+				if err != nil {
+					t.Fatalf("%s, %s: %v", v, spec, err)
+				}
+				for tb, key := range []string{recoverKeyL, recoverKeyR} {
+					if kw := wantKeyword(key, spec); rec.keywords[tb] != kw {
+						t.Errorf("%s, %s, ключ %d: восстановлено %q, ожидается %q", v, spec, tb+1, rec.keywords[tb], kw)
+					}
+					for r, p := range want[i][tb].Pos {
+						if got, ok := rec.tables[tb].Pos[r]; !ok || got != p {
+							t.Errorf("%s, %s, ключ %d: буква %c в ячейке %v, ожидается %v", v, spec, tb+1, r, got, p)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+}
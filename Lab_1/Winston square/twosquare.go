@@ -1,258 +1,197 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
-	"unicode"
-)
 
-const (
-	latinAlphabet    = "abcdefghiklmnopqrstuvwxyz"
-	cyrillicAlphabet = "абвгдеёжзийклмнопрстуфхцчшщыьэюя"
+	"classic"
 )
 
-type tsTable struct {
-	grid [][]rune
-	rows int
-	cols int
-	pos  map[rune][2]int // для быстрого поиска координат буквы в таблице
-}
-
-func buildTable(key string, rows, cols int, alphabet string, norm func(rune) rune) *tsTable {
-	seen := make(map[rune]bool)
-	var order []rune
-
-	// Буквы ключа — по порядку появления, без повторов
-	for _, r := range []rune(key) {
-		nr := norm(r)
-		if strings.ContainsRune(alphabet, nr) && !seen[nr] {
-			seen[nr] = true
-			order = append(order, nr)
-		}
-	}
-	// Оставшиеся буквы алфавита
-	for _, r := range []rune(alphabet) {
-		if !seen[r] {
-			seen[r] = true
-			order = append(order, r)
-		}
+// process шифрует или дешифрует текст шифром двойного квадрата.
+// keyL — ключ для левой (верхней) таблицы, keyR — для правой (нижней).
+func process(text, keyL, keyR string, v classic.Variant, squares classic.Squares, encrypt bool) (string, error) {
+	c, err := classic.NewTwoSquare(classic.TwoSquareKey{Left: keyL, Right: keyR}, v, squares)
+	if err != nil {
+		return "", err
 	}
+	return classic.Process(c, text, encrypt)
+}
 
-	grid := make([][]rune, rows)
-	pos := make(map[rune][2]int)
-	idx := 0
-	for i := range grid {
-		grid[i] = make([]rune, cols)
-		for j := range grid[i] {
-			if idx < len(order) {
-				grid[i][j] = order[idx]
-				pos[order[idx]] = [2]int{i, j}
-				idx++
-			}
-		}
+// cellRune возвращает букву ячейки; невосстановленная ячейка (0) показывается точкой.
+func cellRune(r rune) rune {
+	if r == 0 {
+		return '·'
 	}
-	return &tsTable{grid: grid, rows: rows, cols: cols, pos: pos}
+	return r
 }
 
-// printTable выводит таблицу на экран (для наглядности).
-func (t *tsTable) printTable(title string) {
+// printTable выводит таблицу, в том числе восстановленную частично.
+func printTable(title string, t *classic.Table) {
 	fmt.Printf("  %s:\n", title)
-	for _, row := range t.grid {
+	for _, row := range t.Grid {
 		fmt.Print("    ")
 		for _, r := range row {
-			fmt.Printf("%c ", r)
+			fmt.Printf("%c ", cellRune(r))
 		}
 		fmt.Println()
 	}
 }
 
-// processBigram выполняет над парой букв. Пара таблиц tL и tR соответствует алфавиту, к которому принадлежат буквы a и b.
-func processBigram(a, b rune, tL, tR *tsTable) (rune, rune) {
-	pa := tL.pos[a]
-	pb := tR.pos[b]
-
-	if pa[0] == pb[0] {
-		// Буквы в одной строке — прямоугольник вырождается, замена не производится.
-		return a, b
+// printPair выводит пару таблиц в выбранном расположении: рядом или друг над другом.
+func printPair(t1, t2 *classic.Table, l classic.Layout) {
+	if l == classic.LayoutVertical {
+		printTable("Верхняя (ключ 1)", t1)
+		printTable("Нижняя  (ключ 2)", t2)
+		return
 	}
-	return tL.grid[pa[0]][pb[1]], tR.grid[pb[0]][pa[1]]
-}
-
-// Нормализация и проверка принадлежности к алфавиту
-
-func normLatin(r rune) rune {
-	r = unicode.ToLower(r)
-	if r == 'j' {
-		return 'i'
+	fmt.Println("  Левая (ключ 1) | Правая (ключ 2):")
+	for i := range t1.Grid {
+		fmt.Print("    ")
+		for _, r := range t1.Grid[i] {
+			fmt.Printf("%c ", cellRune(r))
+		}
+		fmt.Print("  ")
+		for _, r := range t2.Grid[i] {
+			fmt.Printf("%c ", cellRune(r))
+		}
+		fmt.Println()
 	}
-	return r
 }
 
-func normCyrillic(r rune) rune {
-	r = unicode.ToLower(r)
-	if r == 'ъ' {
-		return 'ь'
+func printTables(keyL, keyR string, v classic.Variant, squares classic.Squares) {
+	fmt.Println("\nТаблицы шифра, вариант:", v)
+	c, err := classic.NewTwoSquare(classic.TwoSquareKey{Left: keyL, Right: keyR}, v, squares)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
 	}
-	return r
-}
-
-func isLatinLetter(r rune) bool {
-	lr := unicode.ToLower(r)
-	return lr >= 'a' && lr <= 'z'
+	printPair(c.LatL, c.LatR, v.Layout)
+	printPair(c.CyrL, c.CyrR, v.Layout)
+	fmt.Println()
 }
 
-func isCyrillicLetter(r rune) bool {
-	lr := unicode.ToLower(r)
-	return strings.ContainsRune(cyrillicAlphabet, lr) || lr == 'ъ'
+// chooseVariant — диалог выбора варианта шифра.
+func chooseVariant(current classic.Variant) classic.Variant {
+	fmt.Println("Расположение таблиц:")
+	fmt.Println("  1 — Горизонтальное (рядом)")
+	fmt.Println("  2 — Вертикальное (друг над другом)")
+	v := current
+	switch strings.TrimSpace(classic.ReadLine(": ")) {
+	case "1":
+		v.Layout = classic.LayoutHorizontal
+	case "2":
+		v.Layout = classic.LayoutVertical
+	default:
+		fmt.Println("Неверный выбор, расположение не изменено.")
+	}
+	answer := strings.ToLower(strings.TrimSpace(classic.ReadLine("Переставлять буквы результата? (д/н): ")))
+	v.Transposed = answer == "д" || answer == "да" || answer == "y"
+	fmt.Println("Выбран вариант:", v)
+	fmt.Println()
+	return v
 }
 
-// Обработка потока букв
-// Если количество букв нечётное — в конец добавляется заполнитель (filler), который уже должен быть нормализован.
-func processLetters(letters []rune, filler rune, tL, tR *tsTable) []rune {
-	if len(letters)%2 != 0 {
-		letters = append(letters, filler)
+// runFlags обрабатывает вход без меню (флаги -encrypt/-decrypt).
+func runFlags(sf *classic.StreamFlags, keyR string, v classic.Variant, squares classic.Squares) error {
+	c, err := classic.NewTwoSquare(classic.TwoSquareKey{Left: sf.Key, Right: keyR}, v, squares)
+	if err != nil {
+		return err
 	}
-	result := make([]rune, 0, len(letters))
-	for i := 0; i < len(letters); i += 2 {
-		c1, c2 := processBigram(letters[i], letters[i+1], tL, tR)
-		result = append(result, c1, c2)
-	}
-	return result
+	return sf.Run(c)
 }
 
-// Главная функция обработки текста
-
-// process шифрует или дешифрует текст шифром
-// keyL — ключ для левой таблицы, keyR — ключ для правой.
-func process(text, keyL, keyR string) (string, error) {
-	if keyL == "" || keyR == "" {
-		return "", fmt.Errorf("оба ключа не могут быть пустыми")
-	}
-
-	//  Строим пары таблиц (левая / правая) для каждого алфавита
-	tLatL := buildTable(keyL, 5, 5, latinAlphabet, normLatin)
-	tLatR := buildTable(keyR, 5, 5, latinAlphabet, normLatin)
-
-	tCyrL := buildTable(keyL, 4, 8, cyrillicAlphabet, normCyrillic)
-	tCyrR := buildTable(keyR, 4, 8, cyrillicAlphabet, normCyrillic)
-
-	//  Разбираем текст на токены
-	type token struct {
-		r     rune
-		isLat bool
-		isCyr bool
-		upper bool
-	}
-	runes := []rune(text)
-	tokens := make([]token, len(runes))
-	for i, r := range runes {
-		tokens[i] = token{
-			r:     r,
-			isLat: isLatinLetter(r),
-			isCyr: isCyrillicLetter(r),
-			upper: unicode.IsUpper(r),
-		}
-	}
-
-	// Собираем потоки нормализованных букв
-	var latLetters, cyrLetters []rune
-	for _, tok := range tokens {
-		if tok.isLat {
-			latLetters = append(latLetters, normLatin(tok.r))
-		} else if tok.isCyr {
-			cyrLetters = append(cyrLetters, normCyrillic(tok.r))
-		}
-	}
-
-	// Обрабатываем каждый поток
-	latResult := processLetters(latLetters, 'x', tLatL, tLatR)
-	cyrResult := processLetters(cyrLetters, 'а', tCyrL, tCyrR)
-
-	// Восстанавливаем вывод (позиции не-букв сохраняются)
-	var sb strings.Builder
-	latIdx, cyrIdx := 0, 0
-	for _, tok := range tokens {
-		switch {
-		case tok.isLat && latIdx < len(latResult):
-			r := latResult[latIdx]
-			if tok.upper {
-				r = unicode.ToUpper(r)
-			}
-			sb.WriteRune(r)
-			latIdx++
-		case tok.isCyr && cyrIdx < len(cyrResult):
-			r := cyrResult[cyrIdx]
-			if tok.upper {
-				r = unicode.ToUpper(r)
-			}
-			sb.WriteRune(r)
-			cyrIdx++
-		case !tok.isLat && !tok.isCyr:
-			sb.WriteRune(tok.r)
+func main() {
+	sf := classic.RegisterStreamFlags(flag.CommandLine)
+	keyR := flag.String("key2", "", "ключ правой (нижней) таблицы; -key — ключ левой (верхней)")
+	vertical := flag.Bool("vertical", false, "вертикальное расположение таблиц")
+	transposed := flag.Bool("transposed", false, "переставлять буквы результата в биграмме")
+	loadSquares := classic.SquareFlags(flag.CommandLine)
+	flag.Parse()
+	squares, err := loadSquares()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+
+	var v classic.Variant
+	if *vertical {
+		v.Layout = classic.LayoutVertical
+	}
+	v.Transposed = *transposed
+	if sf.Active() {
+		if err := runFlags(sf, *keyR, v, squares); err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
 		}
-	}
-	// Если при нечётном количестве букв был добавлен заполнитель — дописываем его
-	for ; latIdx < len(latResult); latIdx++ {
-		sb.WriteRune(latResult[latIdx])
-	}
-	for ; cyrIdx < len(cyrResult); cyrIdx++ {
-		sb.WriteRune(cyrResult[cyrIdx])
+		return
 	}
 
-	return sb.String(), nil
-}
-
-// Вспомогательные функции ввода/вывода
-
-var stdinScanner = bufio.NewScanner(os.Stdin)
-
-func readLine(prompt string) string {
-	fmt.Print(prompt)
-	stdinScanner.Scan()
-	return stdinScanner.Text()
-}
-
-func printTables(keyL, keyR string) {
-	fmt.Println("\nТаблицы шифра:")
-	tLatL := buildTable(keyL, 5, 5, latinAlphabet, normLatin)
-	tLatR := buildTable(keyR, 5, 5, latinAlphabet, normLatin)
-	tCyrL := buildTable(keyL, 4, 8, cyrillicAlphabet, normCyrillic)
-	tCyrR := buildTable(keyR, 4, 8, cyrillicAlphabet, normCyrillic)
-	tLatL.printTable("Левая  (ключ 1)")
-	tLatR.printTable("Правая (ключ 2)")
-	tCyrL.printTable("Левая  (ключ 1)")
-	tCyrR.printTable("Правая (ключ 2)")
-	fmt.Println()
-}
-
-func main() {
 	fmt.Println()
 
 	for {
-		fmt.Println("1 — Зашифровать / Расшифровать")
-		fmt.Println("2 — Показать таблицы по ключам")
+		fmt.Println("Вариант:", v)
+		fmt.Println("Таблицы:", squares)
+		fmt.Println("1 — Зашифровать")
+		fmt.Println("2 — Расшифровать")
+		fmt.Println("3 — Показать таблицы по ключам")
+		fmt.Println("4 — Выбрать вариант шифра")
+		fmt.Println("5 — Обработать файл")
+		fmt.Println("6 — Восстановить таблицы по открытому тексту")
+		fmt.Println("7 — Таблицы (размеры и объединения букв)")
 		fmt.Println("0 — Выход")
-		choice := strings.TrimSpace(readLine(": "))
+		choice := strings.TrimSpace(classic.ReadLine(": "))
 
 		switch choice {
-		case "1":
-			text := readLine("Введите текст:         ")
-			keyL := strings.TrimSpace(readLine("Введите ключ 1 (левой таблицы):  "))
-			keyR := strings.TrimSpace(readLine("Введите ключ 2 (правой таблицы): "))
+		case "1", "2":
+			encrypt := choice == "1"
+			text := classic.ReadLine("Введите текст:         ")
+			keyL := strings.TrimSpace(classic.ReadLine("Введите ключ 1 (левой/верхней таблицы):  "))
+			keyR := strings.TrimSpace(classic.ReadLine("Введите ключ 2 (правой/нижней таблицы): "))
 
-			result, err := process(text, keyL, keyR)
+			result, err := process(text, keyL, keyR, v, squares, encrypt)
 			if err != nil {
 				fmt.Println("Ошибка:", err)
 				continue
 			}
 			fmt.Printf("\nРезультат: %s\n\n", result)
 
-		case "2":
-			keyL := strings.TrimSpace(readLine("Введите ключ 1 (левой таблицы):  "))
-			keyR := strings.TrimSpace(readLine("Введите ключ 2 (правой таблицы): "))
-			printTables(keyL, keyR)
+		case "3":
+			keyL := strings.TrimSpace(classic.ReadLine("Введите ключ 1 (левой/верхней таблицы):  "))
+			keyR := strings.TrimSpace(classic.ReadLine("Введите ключ 2 (правой/нижней таблицы): "))
+			printTables(keyL, keyR, v, squares)
+
+		case "4":
+			v = chooseVariant(v)
+
+		case "5":
+			job, err := classic.ReadFileJob()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			keyL := strings.TrimSpace(classic.ReadLine("Введите ключ 1 (левой/верхней таблицы):  "))
+			keyR := strings.TrimSpace(classic.ReadLine("Введите ключ 2 (правой/нижней таблицы): "))
+			c, err := classic.NewTwoSquare(classic.TwoSquareKey{Left: keyL, Right: keyR}, v, squares)
+			if err == nil {
+				err = job.Run(c)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+
+		case "6":
+			runRecover(v, squares)
+
+		case "7":
+			sq, err := classic.ChooseSquares(squares)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			squares = sq
+			fmt.Println()
 
 		case "0":
 			fmt.Println("Выход.")
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"classic"
+)
+
+// readTaps запрашивает отводы регистра (пусто — значение по умолчанию).
+func readTaps(prompt string, def []int) ([]int, error) {
+	s := strings.TrimSpace(classic.ReadLine(fmt.Sprintf("%s [%s]: ", prompt, classic.FormatTaps(def))))
+	if s == "" {
+		return def, nil
+	}
+	return classic.ParseTaps(s)
+}
+
+// readUint запрашивает неотрицательное целое (пусто — значение по умолчанию).
+func readUint(prompt string, def uint64) (uint64, error) {
+	s := strings.TrimSpace(classic.ReadLine(fmt.Sprintf("%s [%d]: ", prompt, def)))
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("неверное число %q", s)
+	}
+	return n, nil
+}
+
+// chooseGenerator — диалог выбора генератора гаммы; nil — гамма вводится вручную.
+func chooseGenerator() (*classic.GenConfig, error) {
+	fmt.Println("Гамма:")
+	fmt.Println("  1 — Вводится вручную (повторяется)")
+	fmt.Println("  2 — LFSR")
+	fmt.Println("  3 — Линейный конгруэнтный генератор")
+	fmt.Println("  4 — Генератор Геффе (три LFSR)")
+	var cfg classic.GenConfig
+	var err error
+	switch strings.TrimSpace(classic.ReadLine(": ")) {
+	case "1":
+		return nil, nil
+	case "2":
+		cfg.Kind = classic.GenLFSR
+		cfg.Taps[0], err = readTaps("Отводы (степени многочлена)", classic.DefaultLFSRTaps)
+	case "3":
+		cfg = classic.DefaultLCG
+		if cfg.A, err = readUint("Множитель a", cfg.A); err != nil {
+			return nil, err
+		}
+		if cfg.C, err = readUint("Приращение c", cfg.C); err != nil {
+			return nil, err
+		}
+		cfg.M, err = readUint("Модуль m", cfg.M)
+	case "4":
+		cfg.Kind = classic.GenGeffe
+		for i := range cfg.Taps {
+			cfg.Taps[i], err = readTaps(fmt.Sprintf("Отводы регистра %d", i+1), classic.DefaultGeffeTaps[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("неверный выбор")
+	}
+	if err != nil {
+		return nil, err
+	}
+	// проверяем параметры сразу, а не при первом шифровании
+	if _, err := cfg.New("-"); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
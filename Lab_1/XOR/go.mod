@@ -1,3 +1,7 @@
 module xor
 
-go 1.21.0
+go 1.25.0
+
+require classic v0.0.0
+
+replace classic => ../classic
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"classic"
+)
+
+// Одноразовый блокнот: файл случайных байтов (crypto/rand) и журнал
+// использованных участков рядом с ним (<блокнот>.used). Каждое сообщение
+// шифруется новым участком блокнота; смещение участка записывается в начало
+// шифртекста, поэтому расшифрование берёт ровно те же байты. Участок
+// попадает в журнал до того, как записан шифртекст, и больше не выдаётся.
+
+// padMagic — сигнатура шифртекста; за ней следует смещение (8 байт, big-endian).
+const padMagic = "OTP1"
+
+const padHeaderLen = len(padMagic) + 8
+
+// padRange — использованный участок блокнота [Offset, Offset+Len).
+type padRange struct {
+	Offset, Len int64
+}
+
+func (r padRange) end() int64 { return r.Offset + r.Len }
+
+func (r padRange) overlaps(o padRange) bool {
+	return r.Offset < o.end() && o.Offset < r.end()
+}
+
+// usedPath возвращает путь журнала блокнота.
+func usedPath(pad string) string { return pad + ".used" }
+
+// generatePad создаёт блокнот из size случайных байтов и пустой журнал.
+// Существующий блокнот не перезаписывается: его участки могли уже разойтись.
+func generatePad(path string, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("размер блокнота должен быть положительным")
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("не удалось создать блокнот %q: %w", path, err)
+	}
+	if _, err := io.CopyN(f, rand.Reader, size); err != nil {
+		f.Close()
+		return fmt.Errorf("не удалось заполнить блокнот: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(usedPath(path), []byte("# смещение длина операция время\n"), 0o600)
+}
+
+// readUsed читает журнал блокнота (отсутствующий журнал — блокнот не использовался).
+func readUsed(pad string) ([]padRange, error) {
+	f, err := os.Open(usedPath(pad))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать журнал блокнота: %w", err)
+	}
+	defer f.Close()
+
+	var used []padRange
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var r padRange
+		var err1, err2 error
+		if len(fields) >= 2 {
+			r.Offset, err1 = strconv.ParseInt(fields[0], 10, 64)
+			r.Len, err2 = strconv.ParseInt(fields[1], 10, 64)
+		}
+		if len(fields) < 2 || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("журнал блокнота, строка %d: ожидается «смещение длина»", n)
+		}
+		used = append(used, r)
+	}
+	return used, sc.Err()
+}
+
+// recordUsed дописывает участок в журнал и сбрасывает его на диск.
+func recordUsed(pad string, r padRange, op string) error {
+	f, err := os.OpenFile(usedPath(pad), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть журнал блокнота: %w", err)
+	}
+	_, err = fmt.Fprintf(f, "%d %d %s %s\n", r.Offset, r.Len, op, time.Now().Format(time.RFC3339))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось записать журнал блокнота: %w", err)
+	}
+	return nil
+}
+
+// padSize возвращает размер блокнота.
+func padSize(pad string) (int64, error) {
+	info, err := os.Stat(pad)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось открыть блокнот: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// readPad читает участок блокнота.
+func readPad(pad string, r padRange) ([]byte, error) {
+	f, err := os.Open(pad)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть блокнот: %w", err)
+	}
+	defer f.Close()
+	buf := make([]byte, r.Len)
+	if _, err := f.ReadAt(buf, r.Offset); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать блокнот: %w", err)
+	}
+	return buf, nil
+}
+
+// padEncrypt шифрует data следующим неиспользованным участком блокнота:
+// участок начинается после всех использованных, даже если между ними есть
+// пропуски. Возвращает шифртекст с заголовком.
+func padEncrypt(pad string, data []byte) ([]byte, error) {
+	size, err := padSize(pad)
+	if err != nil {
+		return nil, err
+	}
+	used, err := readUsed(pad)
+	if err != nil {
+		return nil, err
+	}
+	r := padRange{Len: int64(len(data))}
+	for _, u := range used {
+		r.Offset = max(r.Offset, u.end())
+	}
+	if free := size - r.Offset; r.Len > free {
+		return nil, fmt.Errorf("в блокноте осталось %d байт, сообщение занимает %d", free, r.Len)
+	}
+	gamma, err := readPad(pad, r)
+	if err != nil {
+		return nil, err
+	}
+	// участок считается израсходованным ещё до вывода шифртекста
+	if err := recordUsed(pad, r, "encrypt"); err != nil {
+		return nil, err
+	}
+	ct, err := xorGamma(data, gamma, gammaFull)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, padHeaderLen, padHeaderLen+len(ct))
+	copy(out, padMagic)
+	binary.BigEndian.PutUint64(out[len(padMagic):], uint64(r.Offset))
+	return append(out, ct...), nil
+}
+
+// padDecrypt расшифровывает шифртекст участком блокнота, указанным в
+// заголовке, и отмечает участок в журнале получателя. Участок, пересекающийся
+// с другим израсходованным, — признак повторного использования блокнота.
+func padDecrypt(pad string, ct []byte) ([]byte, error) {
+	if len(ct) < padHeaderLen || string(ct[:len(padMagic)]) != padMagic {
+		return nil, fmt.Errorf("нет заголовка одноразового блокнота")
+	}
+	off := binary.BigEndian.Uint64(ct[len(padMagic):padHeaderLen])
+	ct = ct[padHeaderLen:]
+	size, err := padSize(pad)
+	if err != nil {
+		return nil, err
+	}
+	if off > uint64(size) || uint64(len(ct)) > uint64(size)-off {
+		return nil, fmt.Errorf("участок %d–%d выходит за пределы блокнота (%d байт)", off, off+uint64(len(ct)), size)
+	}
+	r := padRange{Offset: int64(off), Len: int64(len(ct))}
+
+	used, err := readUsed(pad)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(used, r) {
+		for _, u := range used {
+			if u.overlaps(r) {
+				return nil, fmt.Errorf("участок %d–%d пересекается с уже использованным %d–%d: блокнот использован повторно",
+					r.Offset, r.end(), u.Offset, u.end())
+			}
+		}
+		if err := recordUsed(pad, r, "decrypt"); err != nil {
+			return nil, err
+		}
+	}
+	gamma, err := readPad(pad, r)
+	if err != nil {
+		return nil, err
+	}
+	return xorGamma(ct, gamma, gammaFull)
+}
+
+// padStatus описывает размер блокнота и расход.
+func padStatus(pad string) (string, error) {
+	size, err := padSize(pad)
+	if err != nil {
+		return "", err
+	}
+	used, err := readUsed(pad)
+	if err != nil {
+		return "", err
+	}
+	var next, total int64
+	for _, u := range used {
+		next = max(next, u.end())
+		total += u.Len
+	}
+	return fmt.Sprintf("размер %d байт, использовано участков: %d (%d байт), свободно со смещения %d: %d байт",
+		size, len(used), total, next, size-next), nil
+}
+
+// readFileOrStdin читает файл целиком («-» — стандартный ввод).
+func readFileOrStdin(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %q: %w", path, err)
+	}
+	return data, nil
+}
+
+// writeFileOrStdout записывает данные в файл («-» — стандартный вывод).
+func writeFileOrStdout(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("не удалось записать %q: %w", path, err)
+	}
+	return nil
+}
+
+// padFile шифрует или расшифровывает файл in блокнотом pad в файл out.
+func padFile(pad, in, out string, encrypt bool) error {
+	data, err := readFileOrStdin(in)
+	if err != nil {
+		return err
+	}
+	if encrypt {
+		data, err = padEncrypt(pad, data)
+	} else {
+		data, err = padDecrypt(pad, data)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileOrStdout(out, data)
+}
+
+// runPad — диалог одноразового блокнота.
+func runPad() {
+	fmt.Println("Одноразовый блокнот:")
+	fmt.Println("  1 — Создать блокнот")
+	fmt.Println("  2 — Зашифровать файл")
+	fmt.Println("  3 — Расшифровать файл")
+	fmt.Println("  4 — Состояние блокнота")
+	choice := strings.TrimSpace(classic.ReadLine(": "))
+	if choice != "1" && choice != "2" && choice != "3" && choice != "4" {
+		fmt.Println("Неверный выбор.")
+		return
+	}
+	pad := strings.TrimSpace(classic.ReadLine("Файл блокнота          : "))
+
+	var err error
+	switch choice {
+	case "1":
+		var size uint64
+		if size, err = readUint("Размер, байт", 1<<20); err == nil {
+			err = generatePad(pad, int64(size))
+		}
+		if err == nil {
+			fmt.Printf("\nБлокнот %s создан (%d байт), журнал — %s\n\n", pad, size, usedPath(pad))
+		}
+	case "2", "3":
+		in := strings.TrimSpace(classic.ReadLine("Входной файл           : "))
+		out := strings.TrimSpace(classic.ReadLine("Выходной файл          : "))
+		if err = padFile(pad, in, out, choice == "2"); err == nil {
+			fmt.Printf("\nРезультат записан в %s\n\n", out)
+		}
+	case "4":
+		var status string
+		if status, err = padStatus(pad); err == nil {
+			fmt.Printf("\n%s: %s\n\n", pad, status)
+		}
+	}
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newTestPad создаёт во временном каталоге блокнот из size байтов.
+func newTestPad(t *testing.T, size int64) string {
+	t.Helper()
+	pad := filepath.Join(t.TempDir(), "pad.bin")
+	if err := generatePad(pad, size); err != nil {
+		t.Fatal(err)
+	}
+	return pad
+}
+
+// padHeader возвращает смещение из заголовка шифртекста.
+func padHeader(ct []byte) uint64 {
+	return binary.BigEndian.Uint64(ct[len(padMagic):padHeaderLen])
+}
+
+// forgePad собирает шифртекст с произвольным смещением и длиной.
+func forgePad(off uint64, n int) []byte {
+	ct := make([]byte, padHeaderLen+n)
+	copy(ct, padMagic)
+	binary.BigEndian.PutUint64(ct[len(padMagic):], off)
+	return ct
+}
+
+// TestPadRoundTrip проверяет, что сообщения получают соседние участки,
+// а расшифрование берёт участок по смещению из заголовка.
+func TestPadRoundTrip(t *testing.T) {
+	pad := newTestPad(t, 64)
+	first, err := padEncrypt(pad, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := padEncrypt(pad, []byte("world!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if padHeader(first) != 0 || padHeader(second) != 5 {
+		t.Errorf("смещения %d и %d, ожидается 0 и 5", padHeader(first), padHeader(second))
+	}
+
+	for _, tc := range []struct {
+		ct   []byte
+		want string
+	}{{second, "world!"}, {first, "hello"}} {
+		got, err := padDecrypt(pad, tc.ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("расшифровано %q, ожидается %q", got, tc.want)
+		}
+	}
+
+	used, err := readUsed(pad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []padRange{{0, 5}, {5, 6}}; !slices.Equal(used, want) {
+		t.Errorf("журнал %v, ожидается %v", used, want)
+	}
+}
+
+// TestPadTooShort проверяет, что сообщение длиннее остатка блокнота
+// отклоняется и не попадает в журнал.
+func TestPadTooShort(t *testing.T) {
+	pad := newTestPad(t, 8)
+	if _, err := padEncrypt(pad, []byte("8 bytes!")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := padEncrypt(pad, []byte("x")); err == nil {
+		t.Error("исчерпанный блокнот: ожидается ошибка")
+	}
+	if _, err := padDecrypt(pad, forgePad(4, 5)); err == nil {
+		t.Error("участок за концом блокнота: ожидается ошибка")
+	}
+	used, err := readUsed(pad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(used) != 1 {
+		t.Errorf("журнал %v, ожидается один участок", used)
+	}
+}
+
+// TestPadReuse проверяет, что участок, пересекающий использованный,
+// отклоняется, а шифрование не выдаёт участок, записанный при расшифровании.
+func TestPadReuse(t *testing.T) {
+	pad := newTestPad(t, 64)
+	if _, err := padEncrypt(pad, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := padDecrypt(pad, forgePad(2, 5)); err == nil {
+		t.Error("пересекающийся участок: ожидается ошибка")
+	}
+	if _, err := padDecrypt(pad, forgePad(20, 5)); err != nil {
+		t.Fatal(err)
+	}
+	ct, err := padEncrypt(pad, []byte("next"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := padHeader(ct); got != 25 {
+		t.Errorf("смещение %d, ожидается 25", got)
+	}
+}
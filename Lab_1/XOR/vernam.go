@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"classic"
+)
+
+// Побайтовое гаммирование (шифр Вернама): C[i] = P[i] XOR G[i].
+// В отличие от gammaCipher обрабатывает любые байты, поэтому подходит для файлов.
+// Операция симметрична: повторное применение той же гаммы восстанавливает данные.
+
+// gammaMode — способ получения гаммы нужной длины из ключа.
+type gammaMode int
+
+const (
+	gammaRepeat gammaMode = iota // ключ повторяется циклически
+	gammaFull                    // ключ не короче данных (одноразовый блокнот)
+)
+
+// xorGamma накладывает гамму на данные.
+func xorGamma(data, gamma []byte, mode gammaMode) ([]byte, error) {
+	if len(gamma) == 0 {
+		return nil, fmt.Errorf("гамма не может быть пустой")
+	}
+	if mode == gammaFull && len(gamma) < len(data) {
+		return nil, fmt.Errorf("гамма короче данных: %d байт против %d", len(gamma), len(data))
+	}
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ gamma[i%len(gamma)]
+	}
+	return out, nil
+}
+
+// readData читает файл целиком; при hexInput содержимое декодируется из hex
+// (пробелы и переводы строк игнорируются).
+func readData(path string, hexInput bool) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %q: %w", path, err)
+	}
+	if !hexInput {
+		return raw, nil
+	}
+	data, err := hex.DecodeString(strings.Join(strings.Fields(string(raw)), ""))
+	if err != nil {
+		return nil, fmt.Errorf("неверный hex-формат в %q: %w", path, err)
+	}
+	return data, nil
+}
+
+// writeData записывает результат в файл в двоичном виде или как hex-строку.
+func writeData(path string, data []byte, hexOutput bool) error {
+	out := data
+	if hexOutput {
+		out = []byte(hex.EncodeToString(data) + "\n")
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return fmt.Errorf("не удалось записать %q: %w", path, err)
+	}
+	return nil
+}
+
+// parseGamma получает байты гаммы из введённой строки в зависимости от источника:
+// "1" — текст (UTF-8), "2" — hex-строка, "3" — путь к файлу с гаммой,
+// "4" — ключ генератора gen, разворачиваемый в n байтов.
+func parseGamma(source, input string, gen *classic.GenConfig, n int) ([]byte, error) {
+	switch source {
+	case "1":
+		return []byte(input), nil
+	case "2":
+		g, err := hex.DecodeString(strings.Join(strings.Fields(input), ""))
+		if err != nil {
+			return nil, fmt.Errorf("неверный hex-формат гаммы: %w", err)
+		}
+		return g, nil
+	case "3":
+		g, err := os.ReadFile(strings.TrimSpace(input))
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать файл гаммы: %w", err)
+		}
+		return g, nil
+	case "4":
+		if gen == nil {
+			return nil, fmt.Errorf("генератор гаммы не выбран (пункт 4 главного меню)")
+		}
+		return gen.Bytes(input, n)
+	default:
+		return nil, fmt.Errorf("неизвестный источник гаммы %q", source)
+	}
+}
+
+// yes трактует ответ пользователя «да/нет» (по умолчанию — нет).
+func yes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "д", "да", "y", "yes", "1":
+		return true
+	}
+	return false
+}
+
+// runVernam — диалог побайтового гаммирования файла; gen — выбранный генератор гаммы (или nil).
+func runVernam(gen *classic.GenConfig) {
+	inPath := strings.TrimSpace(classic.ReadLine("Входной файл           : "))
+	hexIn := yes(classic.ReadLine("Вход в hex? (д/н)      : "))
+	data, err := readData(inPath, hexIn)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+
+	fmt.Println("Источник гаммы: 1 — текст, 2 — hex, 3 — файл, 4 — ключ генератора")
+	source := strings.TrimSpace(classic.ReadLine(": "))
+	gamma, err := parseGamma(source, classic.ReadLine("Гамма                  : "), gen, len(data))
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+
+	mode := gammaFull // гамма генератора уже имеет длину данных
+	if source != "4" {
+		fmt.Println("Режим гаммы: 1 — повторяющаяся, 2 — во всю длину данных")
+		if strings.TrimSpace(classic.ReadLine(": ")) != "2" {
+			mode = gammaRepeat
+		}
+	}
+
+	result, err := xorGamma(data, gamma, mode)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+
+	outPath := strings.TrimSpace(classic.ReadLine("Выходной файл (пусто — вывести hex): "))
+	if outPath == "" {
+		fmt.Printf("\nРезультат (hex): %s\n\n", hex.EncodeToString(result))
+		return
+	}
+	hexOut := yes(classic.ReadLine("Выход в hex? (д/н)     : "))
+	if err := writeData(outPath, result, hexOut); err != nil {
+		fmt.Println("Ошибка:", err)
+		return
+	}
+	fmt.Printf("\nОбработано %d байт, результат записан в %s\n\n", len(result), outPath)
+}
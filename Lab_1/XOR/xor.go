@@ -1,137 +1,208 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
-	"unicode"
-)
 
-const (
-	latinAlphabet    = "abcdefghijklmnopqrstuvwxyz"
-	cyrillicAlphabet = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"
+	"classic"
 )
 
-// alphabetFor возвращает срез рун алфавита для данного символа (или nil)
-func alphabetFor(r rune) []rune {
-	lr := unicode.ToLower(r)
-	if lr >= 'a' && lr <= 'z' {
-		return []rune(latinAlphabet)
-	}
-	for _, c := range []rune(cyrillicAlphabet) {
-		if c == lr {
-			return []rune(cyrillicAlphabet)
-		}
+// gammaCipher шифрует/дешифрует текст гамма-шифром (сложение по модулю k)
+// над набором алфавитов (nil — латиница и русский алфавит). Если задан генератор,
+// key — его ключ, иначе — сама гамма.
+func gammaCipher(text, key string, gen *classic.GenConfig, alphabets classic.Alphabets, encrypt bool) (string, error) {
+	c, err := newCipher(key, gen, alphabets)
+	if err != nil {
+		return "", err
 	}
-	return nil
+	return classic.Process(c, text, encrypt)
 }
 
-// возвращает индекс руны в срезе, или -1
-func indexOf(alpha []rune, r rune) int {
-	for i, c := range alpha {
-		if c == r {
-			return i
-		}
+// newCipher создаёт гамма-шифр с гаммой key или генератором gen, разворачивающим ключ key.
+func newCipher(key string, gen *classic.GenConfig, alphabets classic.Alphabets) (classic.Tracer, error) {
+	if gen != nil {
+		return classic.NewGeneratorGamma(*gen, key, alphabets)
 	}
-	return -1
+	return classic.NewGamma(classic.GammaKey(key), alphabets)
 }
 
-// gammaCipher шифрует/дешифрует текст гамма-шифром (сложение по модулю k)
-func gammaCipher(text, key string, encrypt bool) string {
-	keyRunes := []rune(key)
-	if len(keyRunes) == 0 {
-		return text
+// generatorByName возвращает генератор с параметрами по умолчанию для флага -gen
+// (пусто — гамма задаётся ключом напрямую).
+func generatorByName(name string) (*classic.GenConfig, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "lfsr":
+		return &classic.GenConfig{Kind: classic.GenLFSR, Taps: [3][]int{classic.DefaultLFSRTaps}}, nil
+	case "lcg":
+		cfg := classic.DefaultLCG
+		return &cfg, nil
+	case "geffe":
+		return &classic.GenConfig{Kind: classic.GenGeffe, Taps: classic.DefaultGeffeTaps}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный генератор %q", name)
 	}
-	result := []rune{}
-	keyIdx := 0 // движется только по буквам
-
-	for _, r := range []rune(text) {
-		alpha := alphabetFor(r)
-		if alpha == nil {
-			result = append(result, r) // не буква — оставляем как есть
-			continue
-		}
-
-		isUpper := unicode.IsUpper(r)
-		lr := unicode.ToLower(r)
-		k := len(alpha)
-
-		// позиция символа текста
-		tPos := indexOf(alpha, lr)
+}
 
-		// символ гаммы (циклически), пропускаем не-буквы в ключе
-		var gammaRune rune
-		for {
-			gr := unicode.ToLower(keyRunes[keyIdx%len(keyRunes)])
-			keyIdx++
-			gammaAlpha := alphabetFor(gr)
-			if gammaAlpha != nil {
-				gammaRune = gr
-				break
-			}
-		}
+// runFlags обрабатывает вход без меню (флаги -encrypt/-decrypt);
+// tracePath — файл трассировки.
+func runFlags(sf *classic.StreamFlags, genName, tracePath string, alphabets classic.Alphabets) error {
+	gen, err := generatorByName(genName)
+	if err != nil {
+		return err
+	}
+	c, err := newCipher(sf.Key, gen, alphabets)
+	if err != nil {
+		return err
+	}
+	if tracePath == "" {
+		return sf.Run(c)
+	}
+	done, err := classic.TraceFile(c, tracePath)
+	if err != nil {
+		return err
+	}
+	if err := sf.Run(c); err != nil {
+		done()
+		return err
+	}
+	return done()
+}
 
-		// Ищем позицию гаммы в том же алфавите
-		gammaPos := indexOf(alpha, gammaRune)
-		if gammaPos == -1 {
-			// гамма из другого алфавита — используем модуль от позиции в своём алфавите
-			gammaAlpha := alphabetFor(gammaRune)
-			if gammaAlpha != nil {
-				gammaPos = indexOf(gammaAlpha, gammaRune) % k
-			} else {
-				gammaPos = 0
-			}
+func main() {
+	loadAlphabets := classic.AlphabetFlags(flag.CommandLine)
+	sf := classic.RegisterStreamFlags(flag.CommandLine)
+	genName := flag.String("gen", "", "генератор гаммы без меню: lfsr, lcg, geffe (-key — ключ генератора)")
+	tracePath := flag.String("trace", "", "файл пошаговой трассировки (.csv, .json, иначе таблица; - — на экран)")
+	pad := flag.String("pad", "", "одноразовый блокнот: -encrypt/-decrypt обрабатывают вход как байты")
+	newPad := flag.Int64("newpad", 0, "создать блокнот -pad из указанного числа случайных байтов")
+	flag.Parse()
+	alphabets, err := loadAlphabets()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+	if *newPad > 0 || (*pad != "" && sf.Active()) {
+		var err error
+		switch {
+		case *pad == "":
+			err = fmt.Errorf("не указан файл блокнота -pad")
+		case *newPad > 0:
+			err = generatePad(*pad, *newPad)
+		case sf.Encrypt == sf.Decrypt:
+			err = fmt.Errorf("укажите ровно один из флагов -encrypt и -decrypt")
+		default:
+			err = padFile(*pad, sf.In, sf.Out, sf.Encrypt)
 		}
-
-		var newPos int
-		if encrypt {
-			newPos = (tPos + gammaPos) % k
-		} else {
-			newPos = (tPos - gammaPos + k) % k
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
 		}
-
-		newRune := alpha[newPos]
-		if isUpper {
-			newRune = unicode.ToUpper(newRune)
+		return
+	}
+	if sf.Active() {
+		if err := runFlags(sf, *genName, *tracePath, alphabets); err != nil {
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			os.Exit(1)
 		}
-		result = append(result, newRune)
+		return
 	}
 
-	return string(result)
-}
-
-var stdinScanner = bufio.NewScanner(os.Stdin)
-
-func readLine(prompt string) string {
-	fmt.Print(prompt)
-	stdinScanner.Scan()
-	return stdinScanner.Text()
-}
-
-func main() {
 	fmt.Println()
+	if alphabets != nil {
+		fmt.Println("Алфавиты:", alphabets)
+	}
 
+	var gen *classic.GenConfig // nil — гамма вводится вручную
 	for {
 		fmt.Println("Шифрование методом гаммирования (ТШ = (ТО + ТГ) mod N)")
+		if gen != nil {
+			fmt.Println("Генератор гаммы:", gen)
+		}
 		fmt.Println("1 — Зашифровать")
 		fmt.Println("2 — Дешифровать")
+		fmt.Println("3 — Побайтовое гаммирование файла (XOR, шифр Вернама)")
+		fmt.Println("4 — Генератор гаммы (LFSR, LCG, Геффе)")
+		fmt.Println("5 — Обработать текстовый файл")
+		fmt.Println("6 — Пошаговая трассировка")
+		fmt.Println("7 — Одноразовый блокнот (XOR со случайной гаммой)")
 		fmt.Println("0 — Выход")
-		op := strings.ToUpper(strings.TrimSpace(readLine(": ")))
+		op := strings.ToUpper(strings.TrimSpace(classic.ReadLine(": ")))
 
 		switch op {
 		case "1", "2":
-			text := readLine("Введите текст : ")
-			key := strings.TrimSpace(readLine("Введите гамму : "))
+			text := classic.ReadLine("Введите текст : ")
+			prompt := "Введите гамму : "
+			if gen != nil {
+				prompt = "Ключ генератора: "
+			}
+			key := strings.TrimSpace(classic.ReadLine(prompt))
 
 			encrypt := op == "1"
-			result := gammaCipher(text, key, encrypt)
+			result, err := gammaCipher(text, key, gen, alphabets, encrypt)
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
 
 			if encrypt {
 				fmt.Printf("\nЗашифрованный текст : %s\n\n", result)
 			} else {
 				fmt.Printf("\nРасшифрованный текст: %s\n\n", result)
 			}
+		case "3":
+			runVernam(gen)
+		case "4":
+			g, err := chooseGenerator()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			gen = g
+			fmt.Println()
+		case "5":
+			job, err := classic.ReadFileJob()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			prompt := "Введите гамму : "
+			if gen != nil {
+				prompt = "Ключ генератора: "
+			}
+			c, err := newCipher(strings.TrimSpace(classic.ReadLine(prompt)), gen, alphabets)
+			if err == nil {
+				err = job.Run(c)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+		case "6":
+			var encrypt bool
+			switch strings.TrimSpace(classic.ReadLine("1 — зашифровать, 2 — расшифровать: ")) {
+			case "1":
+				encrypt = true
+			case "2":
+			default:
+				fmt.Println("Неверный выбор, попробуйте снова.")
+				continue
+			}
+			text := classic.ReadLine("Введите текст : ")
+			prompt := "Введите гамму : "
+			if gen != nil {
+				prompt = "Ключ генератора: "
+			}
+			c, err := newCipher(strings.TrimSpace(classic.ReadLine(prompt)), gen, alphabets)
+			if err == nil {
+				err = classic.RunTrace(c, text, encrypt)
+			}
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+			}
+		case "7":
+			runPad()
 		case "0":
 			fmt.Println("Выход.")
 			return
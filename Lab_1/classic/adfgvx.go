@@ -0,0 +1,141 @@
+package classic
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ADFGVX — шифр ADFGVX: буква заменяется метками строки и столбца таблицы,
+// после чего метки переставляются по столбцам под ключом перестановки.
+// Метки первой таблицы — латинские A D F G V X, второй — русские А Д Ф Г В Х
+// (для таблиц не больше 5×5 — ADFGX без V), так что потоки различимы и в
+// шифртексте. Шифртекст выводится группами по пять меток: сначала первый
+// поток, затем второй; регистр и не-буквы открытого текста не сохраняются.
+type ADFGVX struct {
+	Tables  [2]*Table
+	Squares Squares
+	Labels  [2][]rune
+	Order   []int // порядок чтения столбцов (см. ColumnOrder)
+}
+
+// NewADFGVX строит таблицы и порядок столбцов по ключам (нулевое значение
+// squares — ADFGVXSquares). В таблицах не больше 6 строк и столбцов.
+func NewADFGVX(key ADFGVXKey, squares Squares) (*ADFGVX, error) {
+	squares = squares.Or(ADFGVXSquares)
+	if err := squares.Validate(); err != nil {
+		return nil, err
+	}
+	a := &ADFGVX{Squares: squares, Tables: buildSquares(PolybiusKey(key.Square), squares)}
+	for i, s := range squares {
+		if s.Rows > 6 || s.Cols > 6 {
+			return nil, fmt.Errorf("ADFGVX: таблица %s %d×%d, допускается не больше 6×6", s.Name, s.Rows, s.Cols)
+		}
+		labels := [2]string{"adfgvx", "адфгвх"}[i]
+		if s.Rows <= 5 && s.Cols <= 5 {
+			labels = [2]string{"adfgx", "адфгх"}[i]
+		}
+		a.Labels[i] = []rune(labels)
+	}
+	order, err := ColumnOrder(key.Transposition)
+	if err != nil {
+		return nil, err
+	}
+	a.Order = order
+	return a, nil
+}
+
+func (a *ADFGVX) Encrypt(text string) (string, error) { return runAll(a, text, true) }
+func (a *ADFGVX) Decrypt(text string) (string, error) { return runAll(a, text, false) }
+
+// Stream начинает потоковую обработку. Перестановка требует всего
+// сообщения, поэтому метки копятся, а результат выводится при Flush.
+func (a *ADFGVX) Stream(encrypt bool) (Transformer, error) {
+	return &adfgvxStream{a: a, encrypt: encrypt}, nil
+}
+
+type adfgvxStream struct {
+	a       *ADFGVX
+	encrypt bool
+	seq     [2][]rune // метки (при шифровании) или шифртекст потоков
+}
+
+func (st *adfgvxStream) Write(text string) (string, error) {
+	a := st.a
+	if st.encrypt {
+		for _, tok := range a.Squares.Tokenize(text) {
+			i := 0
+			switch {
+			case tok.IsLat:
+			case tok.IsCyr:
+				i = 1
+			default:
+				continue
+			}
+			p := a.Tables[i].Pos[a.Squares[i].Norm(tok.R)]
+			st.seq[i] = append(st.seq[i], a.Labels[i][p[0]], a.Labels[i][p[1]])
+		}
+		return "", nil
+	}
+
+	for _, r := range text {
+		lr := unicode.ToLower(r)
+		switch {
+		case IndexOf(a.Labels[0], lr) >= 0:
+			st.seq[0] = append(st.seq[0], lr)
+		case IndexOf(a.Labels[1], lr) >= 0:
+			st.seq[1] = append(st.seq[1], lr)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return "", fmt.Errorf("символ %q не является меткой ADFGVX", r)
+		}
+	}
+	return "", nil
+}
+
+func (st *adfgvxStream) Flush() (string, error) {
+	var parts []string
+	for i, seq := range st.seq {
+		if len(seq) == 0 {
+			continue
+		}
+		if st.encrypt {
+			parts = append(parts, groups(ColumnarEncrypt(seq, st.a.Order), 5))
+			continue
+		}
+		letters, err := st.a.decode(i, ColumnarDecrypt(seq, st.a.Order))
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, string(letters))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// decode заменяет пары меток потока i буквами таблицы.
+func (a *ADFGVX) decode(i int, seq []rune) ([]rune, error) {
+	if len(seq)%2 != 0 {
+		return nil, fmt.Errorf("нечётное число меток (%d) в потоке %s", len(seq), a.Squares[i].Name)
+	}
+	t := a.Tables[i]
+	out := make([]rune, 0, len(seq)/2)
+	for k := 0; k < len(seq); k += 2 {
+		row, col := IndexOf(a.Labels[i], seq[k]), IndexOf(a.Labels[i], seq[k+1])
+		if row >= t.Rows || col >= t.Cols {
+			return nil, fmt.Errorf("нет ячейки %c%c в таблице %s", unicode.ToUpper(seq[k]), unicode.ToUpper(seq[k+1]), a.Squares[i].Name)
+		}
+		out = append(out, t.Grid[row][col])
+	}
+	return out, nil
+}
+
+// groups записывает метки заглавными группами по n через пробел.
+func groups(seq []rune, n int) string {
+	var sb strings.Builder
+	for k, r := range seq {
+		if k > 0 && k%n == 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
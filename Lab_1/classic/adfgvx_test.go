@@ -0,0 +1,45 @@
+package classic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestADFGVXVector — пример из литературы: таблица
+// NA1C3H8TB2OME5WRPD4F6G7I9J0KLQSUVXYZ, ключ перестановки PRIVACY.
+func TestADFGVXVector(t *testing.T) {
+	a, err := NewADFGVX(ADFGVXKey{Square: "na1c3h8tb2ome5wrpd4f6g7i9j0klqsuvxyz", Transposition: "PRIVACY"}, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := a.Encrypt("ATTACK AT 1200AM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ReplaceAll(enc, " ", ""); !strings.EqualFold(got, "DGDDDAGDDGAFADDFDADVDVFAADVX") {
+		t.Errorf("шифртекст %q, ожидается DGDD DAGD DGAF ADDF DADV DVFA ADVX", enc)
+	}
+	if dec, _ := a.Decrypt(enc); dec != "attackat1200am" {
+		t.Errorf("расшифровано %q, ожидается attackat1200am", dec)
+	}
+}
+
+// TestADFGVXStreams проверяет, что потоки различимы по меткам: латиница
+// и кириллица расшифровываются каждая своей таблицей, регистр и не-буквы
+// не сохраняются.
+func TestADFGVXStreams(t *testing.T) {
+	a, err := NewADFGVX(ADFGVXKey{Square: "key ключ", Transposition: "german"}, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := a.Encrypt("Attack at dawn! Атака на рассвете.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec, _ := a.Decrypt(enc); dec != "attackatdawn атаканарассвете" {
+		t.Errorf("расшифровано %q", dec)
+	}
+	if _, err := a.Decrypt("ADFGZ"); err == nil {
+		t.Error("буква, не являющаяся меткой, принята")
+	}
+}
@@ -0,0 +1,202 @@
+package classic
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Alphabet — алфавит шифров сдвига: упорядоченные символы (их число — модуль)
+// и правила замены символов, которых нет в алфавите (например, ё → е).
+type Alphabet struct {
+	Name    string
+	Letters []rune
+	Map     map[rune]rune
+}
+
+// Alphabets — набор алфавитов. Символ относится к первому алфавиту,
+// в котором он найден (с учётом правил замены); регистр не различается.
+type Alphabets []Alphabet
+
+// Встроенные алфавиты, доступные по имени в описании набора.
+var presets = map[string]string{
+	"latin":     LatinAlphabet,
+	"russian":   CyrillicAlphabet,
+	"ukrainian": "абвгґдеєжзиіїйклмнопрстуфхцчшщьюя",
+	"german":    "abcdefghijklmnopqrstuvwxyzäöüß",
+	"digits":    "0123456789",
+}
+
+// DefaultAlphabets — латиница и русский алфавит (поведение по умолчанию).
+var DefaultAlphabets = Alphabets{
+	{Name: "latin", Letters: []rune(LatinAlphabet)},
+	{Name: "russian", Letters: []rune(CyrillicAlphabet)},
+}
+
+// orDefault подставляет набор по умолчанию вместо пустого.
+func (s Alphabets) orDefault() Alphabets {
+	if len(s) == 0 {
+		return DefaultAlphabets
+	}
+	return s
+}
+
+// Lookup находит алфавит символа и позицию в нём (после замены по правилам).
+func (s Alphabets) Lookup(r rune) (*Alphabet, int) {
+	lr := unicode.ToLower(r)
+	for i := range s {
+		a := &s[i]
+		c := lr
+		if m, ok := a.Map[c]; ok {
+			c = m
+		}
+		if pos := IndexOf(a.Letters, c); pos >= 0 {
+			return a, pos
+		}
+	}
+	return nil, -1
+}
+
+// AlphabetFor возвращает символы алфавита для данного символа (или nil).
+func (s Alphabets) AlphabetFor(r rune) []rune {
+	a, _ := s.Lookup(r)
+	if a == nil {
+		return nil
+	}
+	return a.Letters
+}
+
+// FilterKey оставляет в ключе только символы алфавитов набора.
+func (s Alphabets) FilterKey(key string) []rune {
+	var filtered []rune
+	for _, r := range key {
+		if a, _ := s.Lookup(r); a != nil {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// String перечисляет алфавиты набора.
+func (s Alphabets) String() string {
+	parts := make([]string, len(s))
+	for i, a := range s {
+		parts[i] = fmt.Sprintf("%s (%d)", a.Name, len(a.Letters))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseAlphabet разбирает описание алфавита: имя встроенного алфавита
+// (latin, russian, ukrainian, german, digits) или строку символов по порядку,
+// за которыми через пробел идут правила замены вида «ё=е».
+// Например: «абвгдежзийклмнопрстуфхцчшщъыьэюя ё=е».
+func ParseAlphabet(spec string) (Alphabet, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Alphabet{}, fmt.Errorf("пустое описание алфавита")
+	}
+	a := Alphabet{Name: fields[0]}
+	letters := fields[0]
+	if p, ok := presets[strings.ToLower(letters)]; ok {
+		a.Name, letters = strings.ToLower(letters), p
+	}
+
+	seen := make(map[rune]bool)
+	for _, r := range strings.ToLower(letters) {
+		if seen[r] {
+			return Alphabet{}, fmt.Errorf("алфавит %q: символ %q повторяется", a.Name, r)
+		}
+		seen[r] = true
+		a.Letters = append(a.Letters, r)
+	}
+	if len(a.Letters) < 2 {
+		return Alphabet{}, fmt.Errorf("алфавит %q: нужно хотя бы два символа", a.Name)
+	}
+
+	for _, rule := range fields[1:] {
+		from, to, ok := strings.Cut(strings.ToLower(rule), "=")
+		f, t := []rune(from), []rune(to)
+		if !ok || len(f) != 1 || len(t) != 1 {
+			return Alphabet{}, fmt.Errorf("алфавит %q: правило %q, ожидается вида «ё=е»", a.Name, rule)
+		}
+		if seen[f[0]] {
+			return Alphabet{}, fmt.Errorf("алфавит %q: заменяемый символ %q уже есть в алфавите", a.Name, f[0])
+		}
+		if !seen[t[0]] {
+			return Alphabet{}, fmt.Errorf("алфавит %q: символа %q нет в алфавите", a.Name, t[0])
+		}
+		if a.Map == nil {
+			a.Map = make(map[rune]rune)
+		}
+		a.Map[f[0]] = t[0]
+	}
+	return a, nil
+}
+
+// LoadAlphabets читает набор алфавитов из файла: по одному описанию
+// (см. ParseAlphabet) на строку, пустые строки и строки с # пропускаются.
+func LoadAlphabets(path string) (Alphabets, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл алфавитов: %w", err)
+	}
+	defer f.Close()
+
+	var set Alphabets
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		a, err := ParseAlphabet(text)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		set = append(set, a)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("в файле %q нет алфавитов", path)
+	}
+	return set, nil
+}
+
+// specList — значение повторяемого флага -alphabet.
+type specList []string
+
+func (l *specList) String() string     { return strings.Join(*l, "; ") }
+func (l *specList) Set(v string) error { *l = append(*l, v); return nil }
+
+// AlphabetFlags регистрирует флаги -alphabet (описание алфавита, можно повторять)
+// и -alphabets (файл с описаниями). Возвращённая функция после fs.Parse собирает
+// набор: сначала алфавиты из файла, затем из флагов; nil — набор по умолчанию.
+func AlphabetFlags(fs *flag.FlagSet) func() (Alphabets, error) {
+	var specs specList
+	fs.Var(&specs, "alphabet", "алфавит: имя (latin, russian, ukrainian, german, digits) или символы и правила замены, например \"абвгдежзийклмнопрстуфхцчшщъыьэюя ё=е\"")
+	path := fs.String("alphabets", "", "файл с описаниями алфавитов, по одному на строку")
+
+	return func() (Alphabets, error) {
+		var set Alphabets
+		if *path != "" {
+			loaded, err := LoadAlphabets(*path)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, loaded...)
+		}
+		for _, spec := range specs {
+			a, err := ParseAlphabet(spec)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, a)
+		}
+		return set, nil
+	}
+}
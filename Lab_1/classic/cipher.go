@@ -0,0 +1,52 @@
+package classic
+
+// Cipher — общий интерфейс классических шифров библиотеки.
+// Ключ задаётся при создании шифра (NewVigenere, NewGamma, NewPlayfair,
+// NewTwoSquare, NewFourSquare, NewPolybius, NewBifid, NewTrifid, NewADFGVX,
+// NewColumnar, NewDoubleTransposition), поэтому любой шифр можно использовать
+// через этот интерфейс одинаково.
+type Cipher interface {
+	Encrypt(text string) (string, error)
+	Decrypt(text string) (string, error)
+}
+
+// Типизированные ключи шифров.
+type (
+	VigenereKey      string // ключевое слово (не-буквы игнорируются)
+	GammaKey         string // гамма (не-буквы игнорируются)
+	PlayfairKey      string // ключевое слово таблиц Плейфейра
+	PolybiusKey      string // ключевое слово таблиц квадрата Полибия, Бифида, Трифида
+	TranspositionKey string // ключ столбцовой перестановки
+
+	// TwoSquareKey — ключи двух таблиц двойного квадрата.
+	TwoSquareKey struct {
+		Left  string // ключ левой (верхней) таблицы
+		Right string // ключ правой (нижней) таблицы
+	}
+
+	// FourSquareKey — ключи двух ключевых таблиц четырёх квадратов.
+	FourSquareKey struct {
+		First  string // ключ правой верхней таблицы
+		Second string // ключ левой нижней таблицы
+	}
+
+	// ADFGVXKey — ключ таблицы и ключ перестановки столбцов ADFGVX.
+	ADFGVXKey struct {
+		Square        string // ключевое слово таблицы
+		Transposition string // ключ перестановки столбцов
+	}
+
+	// DoubleTranspositionKey — ключи двух столбцовых перестановок.
+	DoubleTranspositionKey struct {
+		First  string // ключ первой перестановки
+		Second string // ключ второй перестановки
+	}
+)
+
+// Process шифрует (encrypt = true) или дешифрует текст шифром c.
+func Process(c Cipher, text string, encrypt bool) (string, error) {
+	if encrypt {
+		return c.Encrypt(text)
+	}
+	return c.Decrypt(text)
+}
@@ -0,0 +1,270 @@
+package classic
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Тексты для проверки шифров: регистр, знаки и пробелы, обе азбуки,
+// объединяемые буквы (j, ъ, ё), нечётная длина потоков, пустой текст.
+var roundTripTexts = []string{
+	"Hello, World! Привет, мир — съешь ещё этих мягких булок.\n",
+	"The quick brown fox jumps over the lazy dog; Jazz and jukebox.",
+	"Широкая электрификация южных губерний даст мощный толчок подъёму хозяйства.",
+	"Ъ",
+	"x",
+	"",
+}
+
+// normalize — текст, каким его возвращают шифры на таблицах: буквы
+// заменены по объединениям таблиц (j → i, ъ → ь), регистр и не-буквы те же.
+func normalize(text string, squares Squares) string {
+	tokens := squares.Tokenize(text)
+	lat, cyr := Streams(tokens)
+	for i, r := range lat {
+		lat[i] = squares[0].Norm(r)
+	}
+	for i, r := range cyr {
+		cyr[i] = squares[1].Norm(r)
+	}
+	return Restore(tokens, lat, cyr)
+}
+
+// bigramPlain — расшифровка двойного квадрата и четырёх квадратов: к потоку
+// нечётной длины добавлен заполнитель, он оказывается в конце текста.
+func bigramPlain(text string, squares Squares) string {
+	tokens := squares.Tokenize(text)
+	lat, cyr := Streams(tokens)
+	streams := [2][]rune{lat, cyr}
+	for i, letters := range streams {
+		for j, r := range letters {
+			letters[j] = squares[i].Norm(r)
+		}
+		if len(letters)%2 != 0 {
+			streams[i] = append(letters, squares[i].Filler)
+		}
+	}
+	return Restore(tokens, streams[0], streams[1])
+}
+
+// cipherCase — шифр и ожидаемый результат расшифрования зашифрованного текста
+// (nil — шифр проверяется только на границах частей потока).
+type cipherCase struct {
+	name string
+	c    Streamer
+	want func(text string) string
+}
+
+func exact(text string) string { return text }
+
+func onSquares(f func(string, Squares) string, squares Squares) func(string) string {
+	return func(text string) string { return f(text, squares) }
+}
+
+// allCiphers создаёт по шифру каждой реализации Cipher библиотеки для проверки
+// потоковой обработки.
+func allCiphers(t *testing.T) []cipherCase {
+	t.Helper()
+	must := func(c Streamer, err error) Streamer {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	vigenere := must(NewVigenere("lemon лимон", nil))
+	columnar := must(NewColumnar("zebras"))
+	lfsr := GenConfig{Kind: GenLFSR, Taps: [3][]int{DefaultLFSRTaps}}
+	geffe := GenConfig{Kind: GenGeffe, Taps: DefaultGeffeTaps}
+
+	return append(baseCiphers(t),
+		cipherCase{"Виженер, самоключ", must(NewVigenereMode("key ключ", KeyAuto, nil)), nil},
+		cipherCase{"Бофор", must(NewShiftCipher("key ключ", OpBeaufort, KeyRepeat, nil)), nil},
+		cipherCase{"вариант Бофора", must(NewShiftCipher("key ключ", OpVariantBeaufort, KeyRepeat, nil)), nil},
+		cipherCase{"Гронсфельд", must(NewShiftCipher("31415", OpGronsfeld, KeyRepeat, nil)), nil},
+		cipherCase{"гамма LFSR", must(NewGeneratorGamma(lfsr, "key", nil)), nil},
+		cipherCase{"гамма LCG", must(NewGeneratorGamma(DefaultLCG, "key", nil)), nil},
+		cipherCase{"гамма Геффе", must(NewGeneratorGamma(geffe, "key", nil)), nil},
+		cipherCase{"четыре квадрата", must(NewFourSquare(FourSquareKey{"example", "keyword"}, Squares{})), nil},
+		cipherCase{"квадрат Полибия", must(NewPolybius("key ключ", Squares{})), nil},
+		cipherCase{"Бифид", must(NewBifid("key ключ", 5, Squares{})), nil},
+		cipherCase{"Бифид, одним блоком", must(NewBifid("key ключ", 0, Squares{})), nil},
+		cipherCase{"Трифид", must(NewTrifid("key ключ", 5, Squares{})), nil},
+		cipherCase{"ADFGVX", must(NewADFGVX(ADFGVXKey{"key ключ", "german"}, Squares{})), nil},
+		cipherCase{"столбцовая перестановка", columnar, nil},
+		cipherCase{"двойная перестановка", must(NewDoubleTransposition(DoubleTranspositionKey{"zebras", "key"})), nil},
+		cipherCase{"составной шифр", must(NewProduct(vigenere, columnar)), nil},
+	)
+}
+
+// baseCiphers — шифры, с которых начиналась библиотека: Виженер, гамма,
+// Плейфейр и двойной квадрат. Тесты остальных шифров лежат рядом с ними.
+func baseCiphers(t *testing.T) []cipherCase {
+	t.Helper()
+	vigenere, err := NewVigenere("lemon лимон", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gamma, err := NewGamma("гамма gamma", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playfair, err := NewPlayfair("monarchy", Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	playfair.Fillers = FillersLossless
+	horizontal, err := NewTwoSquare(TwoSquareKey{"example", "пример"}, Variant{}, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vertical, err := NewTwoSquare(TwoSquareKey{"example", "пример"}, Variant{Layout: LayoutVertical, Transposed: true}, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []cipherCase{
+		{"Виженер", vigenere, exact},
+		{"гамма", gamma, exact},
+		{"Плейфейр", playfair, exact},
+		{"двойной квадрат", horizontal, onSquares(bigramPlain, DefaultSquares)},
+		{"двойной квадрат, вертикальный", vertical, onSquares(bigramPlain, DefaultSquares)},
+	}
+}
+
+// TestKnownVectors сверяет шифры с примерами из литературы: Виженер и гамма
+// с ключом LEMON, Плейфейр с ключом «playfair example», вертикальный двойной
+// квадрат с ключами EXAMPLE и KEYWORD на таблицах 5×5 без Q.
+func TestKnownVectors(t *testing.T) {
+	noQ, err := ParseSquare("5x5 abcdefghijklmnoprstuvwxyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vigenere, err := NewVigenere("LEMON", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gamma, err := NewGamma("LEMON", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playfair, err := NewPlayfair("playfair example", Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	twoSquare, err := NewTwoSquare(TwoSquareKey{"example", "keyword"}, Variant{Layout: LayoutVertical}, Squares{noQ, mustSquare("cyrillic4x8")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name          string
+		c             Cipher
+		plain, cipher string
+	}{
+		{"Виженер", vigenere, "ATTACKATDAWN", "LXFOPVEFRNHR"},
+		{"гамма", gamma, "ATTACKATDAWN", "LXFOPVEFRNHR"},
+		{"Плейфейр", playfair, "hidethegoldinthetreestump", "bmodzbxdnabekudmuixmmouvif"},
+		{"двойной квадрат", twoSquare, "helpmeobiwankenobi", "hedlxwsdjyanhotkdg"},
+	} {
+		if got, _ := tc.c.Encrypt(tc.plain); got != tc.cipher {
+			t.Errorf("%s: шифртекст %q, ожидается %q", tc.name, got, tc.cipher)
+		}
+	}
+}
+
+// TestRoundTrip проверяет, что расшифрование восстанавливает зашифрованный текст
+// (для двойного квадрата — с точностью до объединённых букв и заполнителя).
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range baseCiphers(t) {
+		for _, text := range roundTripTexts {
+			enc, err := tc.c.Encrypt(text)
+			if err != nil {
+				t.Fatalf("%s, %q: %v", tc.name, text, err)
+			}
+			if len(text) > 10 && enc == text {
+				t.Errorf("%s, %q: шифртекст совпадает с открытым текстом", tc.name, text)
+			}
+			dec, err := tc.c.Decrypt(enc)
+			if err != nil {
+				t.Fatalf("%s, %q: %v", tc.name, enc, err)
+			}
+			if want := tc.want(text); dec != want {
+				t.Errorf("%s: расшифровано %q, ожидается %q", tc.name, dec, want)
+			}
+		}
+	}
+}
+
+// writeChunks обрабатывает текст потоком, подавая его частями по size символов.
+func writeChunks(c Streamer, text string, encrypt bool, size int) (string, error) {
+	tr, err := c.Stream(encrypt)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i += size {
+		out, err := tr.Write(string(runes[i:min(i+size, len(runes))]))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(out)
+	}
+	out, err := tr.Flush()
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(out)
+	return sb.String(), nil
+}
+
+// TestStreamChunks проверяет, что результат потоковой обработки не зависит
+// от того, где проходят границы частей: ключ, незавершённые биграммы и блоки
+// переносятся в следующую часть.
+func TestStreamChunks(t *testing.T) {
+	text := strings.Join(roundTripTexts, " ")
+	for _, tc := range allCiphers(t) {
+		for _, encrypt := range []bool{true, false} {
+			input := text
+			if !encrypt {
+				enc, err := tc.c.Encrypt(text)
+				if err != nil {
+					t.Fatalf("%s: %v", tc.name, err)
+				}
+				input = enc
+			}
+			want, err := runAll(tc.c, input, encrypt)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			for _, size := range []int{1, 2, 3, 7, 64} {
+				got, err := writeChunks(tc.c, input, encrypt, size)
+				if err != nil {
+					t.Fatalf("%s, части по %d: %v", tc.name, size, err)
+				}
+				if got != want {
+					t.Errorf("%s (шифрование %v), части по %d: %q, ожидается %q", tc.name, encrypt, size, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestProcessStreamSplitsRunes проверяет ProcessStream при чтении по одному
+// байту: части входа разрывают многобайтовые символы UTF-8.
+func TestProcessStreamSplitsRunes(t *testing.T) {
+	text := strings.Join(roundTripTexts, " ")
+	for _, tc := range allCiphers(t) {
+		want, err := runAll(tc.c, text, true)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var sb strings.Builder
+		if err := ProcessStream(tc.c, iotest.OneByteReader(strings.NewReader(text)), &sb, true); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if sb.String() != want {
+			t.Errorf("%s: %q, ожидается %q", tc.name, sb.String(), want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Общая библиотека классических шифров Lab_1: алфавиты, нормализация букв,
+// таблицы для биграммных шифров, разбор текста на токены и реализации шифров
+// Виженера, гаммирования, Плейфейра и двойного квадрата за общим интерфейсом Cipher.
+package classic
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Полные алфавиты — для шифров сдвига (Виженер, гаммирование).
+const (
+	LatinAlphabet    = "abcdefghijklmnopqrstuvwxyz"
+	CyrillicAlphabet = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"
+)
+
+// Алфавиты таблиц биграммных шифров: J = I (5×5) и Ъ = Ь (4×8).
+const (
+	LatinSquareAlphabet    = "abcdefghiklmnopqrstuvwxyz"
+	CyrillicSquareAlphabet = "абвгдеёжзийклмнопрстуфхцчшщыьэюя"
+)
+
+// AlphabetFor возвращает срез рун алфавита по умолчанию для данного символа (или nil).
+func AlphabetFor(r rune) []rune {
+	return DefaultAlphabets.AlphabetFor(r)
+}
+
+// IndexOf возвращает индекс руны в срезе, или -1.
+func IndexOf(alpha []rune, r rune) int {
+	for i, c := range alpha {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsLatinLetter сообщает, является ли символ латинской буквой.
+func IsLatinLetter(r rune) bool {
+	lr := unicode.ToLower(r)
+	return lr >= 'a' && lr <= 'z'
+}
+
+// IsCyrillicLetter сообщает, является ли символ буквой русского алфавита.
+func IsCyrillicLetter(r rune) bool {
+	lr := unicode.ToLower(r)
+	return strings.ContainsRune(CyrillicAlphabet, lr)
+}
+
+// NormLatin приводит латинскую букву к алфавиту таблицы 5×5 (J = I).
+func NormLatin(r rune) rune {
+	r = unicode.ToLower(r)
+	if r == 'j' {
+		return 'i'
+	}
+	return r
+}
+
+// NormCyrillic приводит русскую букву к алфавиту таблицы 4×8 (Ъ = Ь).
+func NormCyrillic(r rune) rune {
+	r = unicode.ToLower(r)
+	if r == 'ъ' {
+		return 'ь'
+	}
+	return r
+}
+
+// maxLine — наибольшая длина строки ввода (по умолчанию bufio.Scanner ограничен 64 КБ).
+const maxLine = 16 << 20
+
+var stdinScanner = newStdinScanner()
+
+func newStdinScanner() *bufio.Scanner {
+	sc := bufio.NewScanner(os.Stdin)
+	sc.Buffer(make([]byte, 64<<10), maxLine)
+	return sc
+}
+
+// ReadLine читает строку из стандартного ввода.
+func ReadLine(prompt string) string {
+	fmt.Print(prompt)
+	stdinScanner.Scan()
+	return stdinScanner.Text()
+}
@@ -0,0 +1,94 @@
+package classic
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestColumnarVector — пример из литературы: ключ ZEBRAS, неполная последняя
+// строка без дополнения.
+func TestColumnarVector(t *testing.T) {
+	order, err := ColumnOrder("ZEBRAS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{4, 2, 1, 3, 5, 0}; !slices.Equal(order, want) {
+		t.Errorf("порядок столбцов %v, ожидается %v", order, want)
+	}
+
+	c, err := NewColumnar("ZEBRAS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const plain, cipher = "WEAREDISCOVEREDFLEEATONCE", "EVLNACDTESEAROFODEECWIREE"
+	if got, _ := c.Encrypt(plain); got != cipher {
+		t.Errorf("шифртекст %q, ожидается %q", got, cipher)
+	}
+	if got, _ := c.Decrypt(cipher); got != plain {
+		t.Errorf("расшифровано %q, ожидается %q", got, plain)
+	}
+	// не-буквы остаются на местах, переставляются только буквы
+	if got, _ := c.Encrypt("WE ARE DISCOVERED. FLEE AT ONCE"); got != "EV LNA CDTESEAROF. ODEE CW IREE" {
+		t.Errorf("шифртекст с пробелами %q", got)
+	}
+}
+
+// TestDoubleTransposition проверяет, что двойная перестановка — это две
+// столбцовые подряд, и что расшифрование возвращает текст.
+func TestDoubleTransposition(t *testing.T) {
+	const text = "Double transposition: двойная перестановка!"
+	double, err := NewDoubleTransposition(DoubleTranspositionKey{First: "zebras", Second: "ключ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := NewColumnar("zebras")
+	second, _ := NewColumnar("ключ")
+
+	enc, err := double.Encrypt(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, _ := first.Encrypt(text)
+	if want, _ := second.Encrypt(step); enc != want {
+		t.Errorf("шифртекст %q, ожидается %q", enc, want)
+	}
+	if dec, _ := double.Decrypt(enc); dec != text {
+		t.Errorf("расшифровано %q, ожидается %q", dec, text)
+	}
+	if _, err := NewDoubleTransposition(DoubleTranspositionKey{First: "zebras", Second: "123"}); err == nil {
+		t.Error("ключ без букв принят")
+	}
+}
+
+// TestProduct проверяет порядок этапов составного шифра: при шифровании —
+// замена, затем перестановка, при расшифровании — наоборот.
+func TestProduct(t *testing.T) {
+	const text = "Attack at dawn! Атака на рассвете."
+	vigenere, err := NewVigenere("lemon лимон", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columnar, err := NewColumnar("zebras")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewProduct(vigenere, columnar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := p.Encrypt(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, _ := vigenere.Encrypt(text)
+	if want, _ := columnar.Encrypt(step); enc != want {
+		t.Errorf("шифртекст %q, ожидается %q", enc, want)
+	}
+	if dec, _ := p.Decrypt(enc); dec != text {
+		t.Errorf("расшифровано %q, ожидается %q", dec, text)
+	}
+	if _, err := NewProduct(); err == nil {
+		t.Error("пустая цепочка принята")
+	}
+}
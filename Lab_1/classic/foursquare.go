@@ -0,0 +1,67 @@
+package classic
+
+import "fmt"
+
+// FourSquareBigram шифрует или дешифрует пару букв шифром четырёх квадратов.
+// Таблицы стоят квадратом 2×2: слева вверху и справа внизу — таблицы без ключа
+// (plain), справа вверху — t1 (ключ 1), слева внизу — t2 (ключ 2). При
+// шифровании первая буква ищется в левой верхней таблице, вторая — в правой
+// нижней; результат берётся из t1 в строке первой буквы и столбце второй и из
+// t2 в строке второй и столбце первой. Дешифрование — обратный путь.
+func FourSquareBigram(a, b rune, plain, t1, t2 *Table, encrypt bool) (rune, rune) {
+	if encrypt {
+		pa, pb := plain.Pos[a], plain.Pos[b]
+		return t1.Grid[pa[0]][pb[1]], t2.Grid[pb[0]][pa[1]]
+	}
+	pa, pb := t1.Pos[a], t2.Pos[b]
+	return plain.Grid[pa[0]][pb[1]], plain.Grid[pb[0]][pa[1]]
+}
+
+// FourSquare — шифр четырёх квадратов: для латиницы и кириллицы по таблице
+// без ключа и по две ключевые таблицы (размеры — см. Squares).
+type FourSquare struct {
+	LatPlain, Lat1, Lat2 *Table
+	CyrPlain, Cyr1, Cyr2 *Table
+	Squares              Squares
+}
+
+// NewFourSquare строит таблицы каждого алфавита
+// (нулевое значение squares — таблицы по умолчанию).
+func NewFourSquare(key FourSquareKey, squares Squares) (*FourSquare, error) {
+	if key.First == "" || key.Second == "" {
+		return nil, fmt.Errorf("оба ключа не могут быть пустыми")
+	}
+	squares = squares.orDefault()
+	if err := squares.Validate(); err != nil {
+		return nil, err
+	}
+	return &FourSquare{
+		LatPlain: squares[0].Build(""),
+		Lat1:     squares[0].Build(key.First),
+		Lat2:     squares[0].Build(key.Second),
+		CyrPlain: squares[1].Build(""),
+		Cyr1:     squares[1].Build(key.First),
+		Cyr2:     squares[1].Build(key.Second),
+		Squares:  squares,
+	}, nil
+}
+
+func (f *FourSquare) Encrypt(text string) (string, error) { return runAll(f, text, true) }
+func (f *FourSquare) Decrypt(text string) (string, error) { return runAll(f, text, false) }
+
+// Stream начинает потоковую обработку так же, как у двойного квадрата:
+// нечётный остаток каждого алфавита дополняется заполнителем при Flush.
+func (f *FourSquare) Stream(encrypt bool) (Transformer, error) {
+	sq := f.Squares.orDefault()
+	return &twoSquareTransformer{
+		states: [2]twoSquareState{
+			{bigram: fourSquareBigram(f.LatPlain, f.Lat1, f.Lat2, encrypt), filler: sq[0].Filler, norm: sq[0].Norm},
+			{bigram: fourSquareBigram(f.CyrPlain, f.Cyr1, f.Cyr2, encrypt), filler: sq[1].Filler, norm: sq[1].Norm},
+		},
+		squares: sq,
+	}, nil
+}
+
+func fourSquareBigram(plain, t1, t2 *Table, encrypt bool) func(a, b rune) (rune, rune) {
+	return func(a, b rune) (rune, rune) { return FourSquareBigram(a, b, plain, t1, t2, encrypt) }
+}
@@ -0,0 +1,41 @@
+package classic
+
+import "testing"
+
+// TestFourSquareVector — пример из литературы: ключи EXAMPLE и KEYWORD,
+// таблицы 5×5 без буквы Q.
+func TestFourSquareVector(t *testing.T) {
+	noQ, err := ParseSquare("5x5 abcdefghijklmnoprstuvwxyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFourSquare(FourSquareKey{First: "example", Second: "keyword"}, Squares{noQ, mustSquare("cyrillic4x8")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const plain, cipher = "helpmeobiwankenobi", "fygmkyhobxmfkkkimd"
+	if got, _ := f.Encrypt(plain); got != cipher {
+		t.Errorf("шифртекст %q, ожидается %q", got, cipher)
+	}
+	if got, _ := f.Decrypt(cipher); got != plain {
+		t.Errorf("расшифровано %q, ожидается %q", got, plain)
+	}
+}
+
+// TestFourSquareRoundTrip проверяет расшифрование с таблицами по умолчанию:
+// объединённые буквы заменяются (J → I, Ъ → Ь), к нечётному потоку
+// дописывается заполнитель, регистр и не-буквы сохраняются.
+func TestFourSquareRoundTrip(t *testing.T) {
+	f, err := NewFourSquare(FourSquareKey{First: "example", Second: "ключ"}, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const text, want = "Hello, Jim! Съешь.", "Hello, Iim! Сьешь.а"
+	enc, err := f.Encrypt(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec, _ := f.Decrypt(enc); dec != want {
+		t.Errorf("расшифровано %q, ожидается %q", dec, want)
+	}
+}
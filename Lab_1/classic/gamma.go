@@ -0,0 +1,111 @@
+package classic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gamma — гамма-шифр: сложение позиций букв текста и гаммы по модулю k
+// (k — размер алфавита буквы текста). Гамма повторяется циклически.
+type Gamma struct {
+	key       []rune
+	alphabets Alphabets
+	trace     func(TraceStep)
+}
+
+// NewGamma проверяет гамму и создаёт шифр над набором алфавитов
+// (nil — латиница и русский алфавит).
+func NewGamma(key GammaKey, alphabets Alphabets) (*Gamma, error) {
+	if key == "" {
+		return nil, fmt.Errorf("гамма не может быть пустой")
+	}
+	alphabets = alphabets.orDefault()
+	filtered := alphabets.FilterKey(string(key))
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("гамма должна содержать хотя бы одну букву")
+	}
+	return &Gamma{key: filtered, alphabets: alphabets}, nil
+}
+
+func (g *Gamma) Encrypt(text string) (string, error) { return runAll(g, text, true) }
+func (g *Gamma) Decrypt(text string) (string, error) { return runAll(g, text, false) }
+
+// SetTrace включает трассировку: f вызывается для каждой буквы (nil — выключить).
+func (g *Gamma) SetTrace(f func(TraceStep)) { g.trace = f }
+
+// Stream начинает потоковую обработку; позиция в гамме переносится между частями.
+func (g *Gamma) Stream(encrypt bool) (Transformer, error) {
+	return &shiftTransformer{alphabets: g.alphabets, next: g.alphabets.gammaShifts(g.key), encrypt: encrypt, trace: g.trace}, nil
+}
+
+// shiftTransformer сдвигает каждую букву текста по модулю k (размер её алфавита)
+// на величину next(k); не-буквы остаются как есть, next для них не вызывается.
+// next хранит позицию в гамме и при трассировке заполняет Key и KeyIndex шага.
+type shiftTransformer struct {
+	alphabets Alphabets
+	next      func(k int, step *TraceStep) int
+	encrypt   bool
+	trace     func(TraceStep)
+	letter    int // номер очередной буквы текста
+}
+
+func (t *shiftTransformer) Write(text string) (string, error) {
+	var sb strings.Builder
+	for _, r := range text {
+		// алфавит символа текста (с учётом правил замены)
+		a, _ := t.alphabets.Lookup(r)
+		if a == nil {
+			sb.WriteRune(r) // не буква — оставляем как есть
+			continue
+		}
+
+		var step *TraceStep
+		if t.trace != nil {
+			step = &TraceStep{Index: t.letter}
+		}
+		gammaPos := t.next(len(a.Letters), step)
+		sb.WriteRune(t.alphabets.shiftLetter(r, gammaPos, OpVigenere, t.encrypt, step))
+		if step != nil {
+			t.trace(*step)
+		}
+		t.letter++
+	}
+	return sb.String(), nil
+}
+
+func (t *shiftTransformer) Flush() (string, error) { return "", nil }
+
+// GammaCipher шифрует/дешифрует текст гамма-шифром (алфавиты по умолчанию).
+func GammaCipher(text string, gamma []rune, encrypt bool) string {
+	return DefaultAlphabets.GammaCipher(text, gamma, encrypt)
+}
+
+// GammaCipher шифрует/дешифрует текст гамма-шифром (сложение по модулю k).
+// gamma должна состоять только из символов алфавитов набора (см. FilterKey).
+func (s Alphabets) GammaCipher(text string, gamma []rune, encrypt bool) string {
+	if len(gamma) == 0 {
+		return text
+	}
+	t := &shiftTransformer{alphabets: s, next: s.gammaShifts(gamma), encrypt: encrypt}
+	out, _ := t.Write(text)
+	return out
+}
+
+// gammaShifts возвращает функцию, выдающую сдвиги очередных букв по циклической гамме.
+func (s Alphabets) gammaShifts(gamma []rune) func(k int, step *TraceStep) int {
+	keyIdx := 0 // движется только по буквам
+	return func(k int, step *TraceStep) int {
+		// символ гаммы (циклически)
+		i := keyIdx % len(gamma)
+		gammaRune := gamma[i]
+		keyIdx++
+		if step != nil {
+			step.Key, step.KeyIndex = string(gammaRune), i
+		}
+
+		// Позиция гаммы в своём алфавите; если это другой алфавит —
+		// берём её по модулю k
+		_, gammaPos := s.Lookup(gammaRune)
+		return gammaPos % k
+	}
+}
@@ -0,0 +1,276 @@
+package classic
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Генераторы псевдослучайной гаммы: короткий ключ задаёт начальное состояние,
+// а генератор разворачивает его в неповторяющуюся (в пределах периода) гамму.
+
+// Generator — источник байтов гаммы.
+type Generator interface {
+	Byte() byte
+}
+
+// GenKind — тип генератора.
+type GenKind int
+
+const (
+	GenLFSR  GenKind = iota // регистр сдвига с линейной обратной связью
+	GenLCG                  // линейный конгруэнтный генератор
+	GenGeffe                // генератор Геффе: три LFSR и нелинейная комбинирующая функция
+)
+
+// GenConfig — параметры генератора. Начальное состояние берётся из ключа (см. New).
+type GenConfig struct {
+	Kind GenKind
+	Taps [3][]int // отводы регистров (степени многочлена); для LFSR используется Taps[0]
+	A    uint64   // множитель LCG
+	C    uint64   // приращение LCG
+	M    uint64   // модуль LCG
+}
+
+// Параметры по умолчанию: примитивные многочлены x³²+x²²+x²+x+1, x¹⁷+x¹⁴+1,
+// x¹⁹+x¹⁸+x¹⁷+x¹⁴+1, x²³+x¹⁸+1 и LCG из «Numerical Recipes».
+var (
+	DefaultLFSRTaps  = []int{32, 22, 2, 1}
+	DefaultGeffeTaps = [3][]int{{17, 14}, {19, 18, 17, 14}, {23, 18}}
+	DefaultLCG       = GenConfig{Kind: GenLCG, A: 1664525, C: 1013904223, M: 1 << 32}
+)
+
+func (c GenConfig) String() string {
+	switch c.Kind {
+	case GenLCG:
+		return fmt.Sprintf("LCG (a = %d, c = %d, m = %d)", c.A, c.C, c.M)
+	case GenGeffe:
+		return fmt.Sprintf("Геффе (%s; %s; %s)", FormatTaps(c.Taps[0]), FormatTaps(c.Taps[1]), FormatTaps(c.Taps[2]))
+	default:
+		return "LFSR (" + FormatTaps(c.Taps[0]) + ")"
+	}
+}
+
+// ParseTaps разбирает отводы: степени через запятую или пробел («32,22,2,1»)
+// либо многочлен («x^32+x^22+x^2+x+1»). Свободный член 1 можно не указывать.
+func ParseTaps(s string) ([]int, error) {
+	s = strings.ToLower(s)
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '+' || r == ' '
+	})
+	var taps []int
+	for _, f := range fields {
+		switch {
+		case f == "1":
+			if strings.Contains(s, "x") {
+				continue // свободный член многочлена
+			}
+		case f == "x":
+			f = "1"
+		default:
+			f = strings.TrimPrefix(f, "x^")
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 1 || n > 64 {
+			return nil, fmt.Errorf("неверный отвод %q: ожидается степень от 1 до 64", f)
+		}
+		if !slices.Contains(taps, n) {
+			taps = append(taps, n)
+		}
+	}
+	if len(taps) == 0 {
+		return nil, fmt.Errorf("не заданы отводы регистра")
+	}
+	slices.Sort(taps)
+	slices.Reverse(taps)
+	return taps, nil
+}
+
+// FormatTaps записывает отводы в виде многочлена.
+func FormatTaps(taps []int) string {
+	var sb strings.Builder
+	for _, t := range taps {
+		if t == 1 {
+			sb.WriteString("x+")
+		} else {
+			fmt.Fprintf(&sb, "x^%d+", t)
+		}
+	}
+	sb.WriteString("1")
+	return sb.String()
+}
+
+// seed выводит из ключа 64-битное начальное состояние (FNV-1a);
+// salt различает регистры генератора Геффе.
+func seed(key string, salt byte) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte{salt})
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// New создаёт генератор, начальное состояние которого задано ключом.
+func (c GenConfig) New(key string) (Generator, error) {
+	if key == "" {
+		return nil, fmt.Errorf("ключ генератора не может быть пустым")
+	}
+	switch c.Kind {
+	case GenLFSR:
+		return newLFSR(c.Taps[0], seed(key, 0))
+	case GenLCG:
+		if c.M < 2 || c.A == 0 {
+			return nil, fmt.Errorf("параметры LCG: нужны m ≥ 2 и a > 0")
+		}
+		return &lcg{a: c.A % c.M, c: c.C % c.M, m: c.M, state: seed(key, 0) % c.M}, nil
+	case GenGeffe:
+		var g geffe
+		for i := range g.r {
+			r, err := newLFSR(c.Taps[i], seed(key, byte(i+1)))
+			if err != nil {
+				return nil, fmt.Errorf("регистр %d: %w", i+1, err)
+			}
+			g.r[i] = r
+		}
+		return &g, nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип генератора")
+	}
+}
+
+// Bytes возвращает n байтов гаммы, порождённых ключом.
+func (c GenConfig) Bytes(key string, n int) ([]byte, error) {
+	g, err := c.New(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = g.Byte()
+	}
+	return out, nil
+}
+
+// lfsr — регистр Фибоначчи: младший бит выдаётся, старший получает
+// сумму по модулю 2 битов на отводах (степень t — бит n−t).
+type lfsr struct {
+	n     int
+	mask  uint64
+	state uint64
+}
+
+func newLFSR(taps []int, s uint64) (*lfsr, error) {
+	if len(taps) == 0 {
+		return nil, fmt.Errorf("не заданы отводы регистра")
+	}
+	n := slices.Max(taps)
+	r := &lfsr{n: n}
+	for _, t := range taps {
+		r.mask |= 1 << (n - t)
+	}
+	if n < 64 {
+		s &= 1<<n - 1
+	}
+	if s == 0 {
+		s = 1 // нулевое состояние регистр не покидает
+	}
+	r.state = s
+	return r, nil
+}
+
+func (r *lfsr) bit() byte {
+	out := byte(r.state & 1)
+	fb := uint64(bits.OnesCount64(r.state&r.mask) & 1)
+	r.state = r.state>>1 | fb<<(r.n-1)
+	return out
+}
+
+func (r *lfsr) Byte() byte {
+	var b byte
+	for range 8 {
+		b = b<<1 | r.bit()
+	}
+	return b
+}
+
+// lcg — x(i+1) = (a·x(i) + c) mod m; выдаются старшие биты состояния,
+// так как младшие у LCG с m = 2^k имеют короткий период.
+type lcg struct {
+	a, c, m, state uint64
+}
+
+func (g *lcg) Byte() byte {
+	hi, lo := bits.Mul64(g.a, g.state)
+	prod := bits.Rem64(hi, lo, g.m)
+	sum, carry := bits.Add64(prod, g.c, 0)
+	g.state = bits.Rem64(carry, sum, g.m)
+	hi, lo = bits.Mul64(g.state, 256)
+	q, _ := bits.Div64(hi, lo, g.m)
+	return byte(q)
+}
+
+// geffe — f(x1, x2, x3) = x1·x2 ⊕ ¬x1·x3: первый регистр выбирает,
+// чей бит попадёт в гамму.
+type geffe struct {
+	r [3]*lfsr
+}
+
+func (g *geffe) Byte() byte {
+	var b byte
+	for range 8 {
+		x1, x2, x3 := g.r[0].bit(), g.r[1].bit(), g.r[2].bit()
+		b = b<<1 | (x1&x2 ^ (1^x1)&x3)
+	}
+	return b
+}
+
+// GeneratorGamma — гамма-шифр над алфавитами, у которого сдвиг каждой буквы
+// берётся из генератора по модулю размера алфавита буквы.
+type GeneratorGamma struct {
+	cfg       GenConfig
+	key       string
+	alphabets Alphabets
+	trace     func(TraceStep)
+}
+
+// NewGeneratorGamma проверяет параметры генератора и создаёт шифр
+// (alphabets = nil — латиница и русский алфавит).
+func NewGeneratorGamma(cfg GenConfig, key string, alphabets Alphabets) (*GeneratorGamma, error) {
+	if _, err := cfg.New(key); err != nil {
+		return nil, err
+	}
+	return &GeneratorGamma{cfg: cfg, key: key, alphabets: alphabets.orDefault()}, nil
+}
+
+func (g *GeneratorGamma) Encrypt(text string) (string, error) { return runAll(g, text, true) }
+func (g *GeneratorGamma) Decrypt(text string) (string, error) { return runAll(g, text, false) }
+
+// SetTrace включает трассировку: f вызывается для каждой буквы (nil — выключить).
+// Вместо буквы ключа в шаг записывается 16-битное число гаммы, KeyIndex — номер сдвига.
+func (g *GeneratorGamma) SetTrace(f func(TraceStep)) { g.trace = f }
+
+// Stream начинает потоковую обработку со свежего состояния генератора.
+func (g *GeneratorGamma) Stream(encrypt bool) (Transformer, error) {
+	gen, err := g.cfg.New(g.key)
+	if err != nil {
+		return nil, err
+	}
+	// сдвиг — 16-битное число из двух байтов гаммы по модулю k; значения из
+	// неполного последнего диапазона отбрасываются, чтобы сдвиги были равновероятны
+	drawn := 0
+	next := func(k int, step *TraceStep) int {
+		limit := 1<<16 - 1<<16%k
+		for {
+			if v := int(gen.Byte())<<8 | int(gen.Byte()); v < limit {
+				if step != nil {
+					step.Key, step.KeyIndex = strconv.Itoa(v), drawn
+				}
+				drawn++
+				return v % k
+			}
+		}
+	}
+	return &shiftTransformer{alphabets: g.alphabets, next: next, encrypt: encrypt, trace: g.trace}, nil
+}
@@ -0,0 +1,110 @@
+package classic
+
+import (
+	"encoding/hex"
+	"slices"
+	"testing"
+)
+
+// TestLCGSequence сверяет LCG с параметрами из «Numerical Recipes» с известной
+// последовательностью от нулевого состояния: 1013904223, 1196435762,
+// 3519870697, 2868466484 — байт гаммы равен старшему байту состояния.
+func TestLCGSequence(t *testing.T) {
+	g := &lcg{a: DefaultLCG.A, c: DefaultLCG.C, m: DefaultLCG.M}
+	for i, want := range []byte{0x3c, 0x47, 0xd1, 0xaa} {
+		if got := g.Byte(); got != want {
+			t.Errorf("байт %d: %#02x, ожидается %#02x", i, got, want)
+		}
+	}
+}
+
+// TestLFSRPeriod проверяет, что регистр с примитивным многочленом x⁴+x³+1
+// проходит все 15 ненулевых состояний.
+func TestLFSRPeriod(t *testing.T) {
+	r, err := newLFSR([]int{4, 3}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[uint64]bool)
+	for range 15 {
+		seen[r.state] = true
+		r.bit()
+	}
+	if len(seen) != 15 || r.state != 1 {
+		t.Errorf("пройдено состояний %d, конечное %04b; ожидается 15 и 0001", len(seen), r.state)
+	}
+}
+
+// TestGeneratorPrefixes фиксирует начало гаммы генераторов по умолчанию для
+// ключа «key»: изменение вывода начального состояния или отводов сделает
+// старые шифртексты нерасшифровываемыми.
+func TestGeneratorPrefixes(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  GenConfig
+		want string
+	}{
+		{GenConfig{Kind: GenLFSR, Taps: [3][]int{DefaultLFSRTaps}}, "63bb0e53bfb5db8a"},
+		{DefaultLCG, "24c8a5f561d35867"},
+		{GenConfig{Kind: GenGeffe, Taps: DefaultGeffeTaps}, "15319e3610b9f914"},
+	} {
+		b, err := tc.cfg.Bytes("key", 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(b); got != tc.want {
+			t.Errorf("%s: гамма %s, ожидается %s", tc.cfg, got, tc.want)
+		}
+	}
+}
+
+func TestParseTaps(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []int
+	}{
+		{"32,22,2,1", []int{32, 22, 2, 1}},
+		{"32 22 2 1", []int{32, 22, 2, 1}},
+		{"x^32+x^22+x^2+x+1", []int{32, 22, 2, 1}},
+		{"x^17+x^14", []int{17, 14}},
+		{"X^16+X^14+1", []int{16, 14}},
+	} {
+		got, err := ParseTaps(tc.in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%q: %v, ожидается %v", tc.in, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "0", "65", "x^y"} {
+		if _, err := ParseTaps(bad); err == nil {
+			t.Errorf("%q: отводы приняты", bad)
+		}
+	}
+}
+
+func TestGeneratorGammaRoundTrip(t *testing.T) {
+	const text = "Gamma from a generator: гамма из генератора, 2024."
+	for _, cfg := range []GenConfig{
+		{Kind: GenLFSR, Taps: [3][]int{DefaultLFSRTaps}},
+		DefaultLCG,
+		{Kind: GenGeffe, Taps: DefaultGeffeTaps},
+	} {
+		g, err := NewGeneratorGamma(cfg, "key", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := g.Encrypt(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := g.Decrypt(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc == text || dec != text {
+			t.Errorf("%s: шифртекст %q, расшифровано %q", cfg, enc, dec)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package classic
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Встроенные таблицы квадграмм в распространённом формате «QUAD число»,
+// посчитанные по связной прозе: english.txt — 440 тыс. букв «Оптики» Ньютона
+// (издание 1730 г., текст проекта «Гутенберг» из исходников Go,
+// src/testdata/Isaac.Newton-Opticks.txt) и Геттисбергской речи Линкольна
+// (src/compress/testdata/gettysburg.txt), russian.txt — 86 тыс. букв
+// рассказов и очерков, написанных для этой таблицы. Учитываются только
+// буквы, подряд, без пробелов.
+//
+//go:embed corpus/english.txt
+var englishQuadgrams string
+
+//go:embed corpus/russian.txt
+var russianQuadgrams string
+
+// builtinQuadgrams — встроенные таблицы по именам алфавитов (см. DefaultAlphabets).
+var builtinQuadgrams = map[string]string{
+	"latin":   englishQuadgrams,
+	"russian": russianQuadgrams,
+}
+
+// Quadgrams — числа вхождений квадграмм из букв алфавита. Буквы нумеруются
+// по порядку в алфавите, квадграмма abcd имеет номер ((a·n + b)·n + c)·n + d.
+type Quadgrams struct {
+	Counts []float64
+	Total  float64
+
+	n     int
+	index map[rune]int
+	norm  func(rune) rune
+}
+
+// ReadQuadgrams читает таблицу «QUAD число» (по одной квадграмме на строку)
+// для алфавита alphabet. Символы приводятся к нижнему регистру и norm
+// (объединения вроде J = I складывают числа), квадграммы с другими символами
+// пропускаются.
+func ReadQuadgrams(r io.Reader, alphabet string, norm func(rune) rune) (*Quadgrams, error) {
+	letters := []rune(alphabet)
+	q := &Quadgrams{n: len(letters), index: make(map[rune]int, len(letters)), norm: norm}
+	for i, l := range letters {
+		q.index[l] = i
+	}
+	q.Counts = make([]float64, q.n*q.n*q.n*q.n)
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("строка %d: ожидается «квадграмма число»", line)
+		}
+		cnt, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		g := q.Indices(fields[0])
+		if len(g) != 4 {
+			continue
+		}
+		q.Counts[((g[0]*q.n+g[1])*q.n+g[2])*q.n+g[3]] += cnt
+		q.Total += cnt
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if q.Total == 0 {
+		return nil, fmt.Errorf("в таблице нет квадграмм для данного алфавита")
+	}
+	return q, nil
+}
+
+// LoadQuadgrams читает таблицу квадграмм из файла (см. ReadQuadgrams).
+func LoadQuadgrams(path, alphabet string, norm func(rune) rune) (*Quadgrams, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл квадграмм: %w", err)
+	}
+	defer f.Close()
+	return ReadQuadgrams(f, alphabet, norm)
+}
+
+// BuiltinQuadgrams возвращает встроенную таблицу языка алфавита name
+// («latin» — английский, «russian» — русский).
+func BuiltinQuadgrams(name, alphabet string, norm func(rune) rune) (*Quadgrams, error) {
+	table, ok := builtinQuadgrams[name]
+	if !ok {
+		return nil, fmt.Errorf("нет встроенной таблицы квадграмм для алфавита %q", name)
+	}
+	return ReadQuadgrams(strings.NewReader(table), alphabet, norm)
+}
+
+// Size — число букв алфавита.
+func (q *Quadgrams) Size() int { return q.n }
+
+// Index возвращает номер буквы r (после приведения) или -1.
+func (q *Quadgrams) Index(r rune) int {
+	if i, ok := q.index[q.norm(unicode.ToLower(r))]; ok {
+		return i
+	}
+	return -1
+}
+
+// Indices оставляет в тексте только буквы алфавита в виде номеров.
+func (q *Quadgrams) Indices(text string) []int {
+	var out []int
+	for _, r := range text {
+		if i := q.Index(r); i >= 0 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// LogProbs возвращает log10 вероятностей квадграмм; невстреченным
+// достаётся log10(0.01 / всего).
+func (q *Quadgrams) LogProbs() []float32 {
+	logp := make([]float32, len(q.Counts))
+	floor := float32(math.Log10(0.01 / q.Total))
+	for i, c := range q.Counts {
+		if c > 0 {
+			logp[i] = float32(math.Log10(c / q.Total))
+		} else {
+			logp[i] = floor
+		}
+	}
+	return logp
+}
+
+// Marginal возвращает числа вхождений k-грамм (1 ≤ k ≤ 4): суммы по
+// квадграммам, которые с них начинаются (для k = 4 — сам Counts).
+func (q *Quadgrams) Marginal(k int) []float64 {
+	counts := q.Counts
+	for size := 4; size > k; size-- {
+		shorter := make([]float64, len(counts)/q.n)
+		for i, c := range counts {
+			shorter[i/q.n] += c
+		}
+		counts = shorter
+	}
+	return counts
+}
@@ -0,0 +1,537 @@
+package classic
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PlayfairTable — таблица Плейфейра с правилами замены биграмм.
+type PlayfairTable struct {
+	*Table
+}
+
+// EncBigram шифрует пару букв по правилам Плейфейра.
+func (t PlayfairTable) EncBigram(a, b rune) (rune, rune) {
+	pa, pb := t.Pos[a], t.Pos[b]
+	switch {
+	case pa[0] == pb[0]: // одна строка — сдвиг вправо
+		return t.Grid[pa[0]][(pa[1]+1)%t.Cols],
+			t.Grid[pb[0]][(pb[1]+1)%t.Cols]
+	case pa[1] == pb[1]: // один столбец — сдвиг вниз
+		return t.Grid[(pa[0]+1)%t.Rows][pa[1]],
+			t.Grid[(pb[0]+1)%t.Rows][pb[1]]
+	default: // прямоугольник — меняем столбцы
+		return t.Grid[pa[0]][pb[1]], t.Grid[pb[0]][pa[1]]
+	}
+}
+
+// DecBigram дешифрует пару букв по правилам Плейфейра.
+func (t PlayfairTable) DecBigram(a, b rune) (rune, rune) {
+	pa, pb := t.Pos[a], t.Pos[b]
+	switch {
+	case pa[0] == pb[0]:
+		return t.Grid[pa[0]][(pa[1]-1+t.Cols)%t.Cols],
+			t.Grid[pb[0]][(pb[1]-1+t.Cols)%t.Cols]
+	case pa[1] == pb[1]:
+		return t.Grid[(pa[0]-1+t.Rows)%t.Rows][pa[1]],
+			t.Grid[(pb[0]-1+t.Rows)%t.Rows][pb[1]]
+	default: // прямоугольник — меняем столбцы
+		return t.Grid[pa[0]][pb[1]], t.Grid[pb[0]][pa[1]]
+	}
+}
+
+// PrepareBigrams подготавливает срез букв к шифрованию таблицей sq: нормализует
+// их, разделяет заполнителем одинаковые буквы в паре и дополняет до чётной длины.
+func PrepareBigrams(letters []rune, sq Square) []rune {
+	var result []rune
+	var pending pairState
+	for _, r := range letters {
+		pair, _ := pending.add(sq.Norm(r), sq)
+		result = append(result, pair...)
+	}
+	pair, _ := pending.finish(sq)
+	return append(result, pair...)
+}
+
+// pairState — незавершённая биграмма при подготовке текста к шифрованию.
+type pairState struct {
+	a   rune
+	has bool
+}
+
+// add принимает нормализованную букву и возвращает готовую биграмму (или nil)
+// и признак того, что вторая её буква — вставленный заполнитель.
+// Одинаковые буквы разделяются заполнителем, вторая остаётся для следующей пары.
+func (p *pairState) add(c rune, sq Square) ([]rune, bool) {
+	if !p.has {
+		p.a, p.has = c, true
+		return nil, false
+	}
+	a := p.a
+	if a == c {
+		return []rune{a, fillerFor(a, sq)}, true
+	}
+	p.has = false
+	return []rune{a, c}, false
+}
+
+// finish дополняет незавершённую биграмму заполнителем.
+func (p *pairState) finish(sq Square) ([]rune, bool) {
+	if !p.has {
+		return nil, false
+	}
+	p.has = false
+	return []rune{p.a, fillerFor(p.a, sq)}, true
+}
+
+// fillerFor — заполнитель после буквы a (запасной, если a — сам заполнитель).
+func fillerFor(a rune, sq Square) rune {
+	if a == sq.Filler {
+		return sq.AltFiller
+	}
+	return sq.Filler
+}
+
+// isFiller сообщает, мог ли символ cur, стоящий вторым в биграмме после prev,
+// быть вставлен PrepareBigrams: он равен заполнителю (или запасному заполнителю
+// после самого заполнителя) и либо разделяет одинаковые буквы (next == prev),
+// либо завершает поток (last).
+func isFiller(prev, cur, next rune, last bool, sq Square) bool {
+	if cur != fillerFor(prev, sq) {
+		return false
+	}
+	return last || next == prev
+}
+
+// StripFillers эвристически удаляет заполнители из расшифрованного потока.
+// Настоящая буква, совпавшая по положению с заполнителем (например, x
+// в «axa»), тоже будет удалена — для точного результата нужна отметка заполнителей.
+func StripFillers(letters []rune, sq Square) []rune {
+	out := make([]rune, 0, len(letters))
+	for i, r := range letters {
+		if i%2 == 1 {
+			last := i == len(letters)-1
+			var next rune
+			if !last {
+				next = letters[i+1]
+			}
+			if isFiller(letters[i-1], r, next, last, sq) {
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// PlayfairStream шифрует или дешифрует поток букв одного алфавита таблицей t,
+// построенной по sq. Заполнители при расшифровании остаются в результате.
+func PlayfairStream(letters []rune, t PlayfairTable, sq Square, encrypt bool) ([]rune, error) {
+	if encrypt {
+		prepared := PrepareBigrams(letters, sq)
+		return encryptBigrams(prepared, t), nil
+	}
+	return decryptBigrams(letters, t, sq.Norm)
+}
+
+func encryptBigrams(prepared []rune, t PlayfairTable) []rune {
+	result := make([]rune, 0, len(prepared))
+	for i := 0; i < len(prepared); i += 2 {
+		a, b := t.EncBigram(prepared[i], prepared[i+1])
+		result = append(result, a, b)
+	}
+	return result
+}
+
+func decryptBigrams(letters []rune, t PlayfairTable, norm func(rune) rune) ([]rune, error) {
+	if len(letters) == 0 {
+		return nil, nil
+	}
+	normalized := make([]rune, 0, len(letters))
+	for _, r := range letters {
+		normalized = append(normalized, norm(r))
+	}
+	if len(normalized)%2 != 0 {
+		return nil, fmt.Errorf("длина зашифрованного текста должна быть чётной (шифр Плейфейра работает с парами)")
+	}
+	result := make([]rune, 0, len(normalized))
+	for i := 0; i < len(normalized); i += 2 {
+		a, b := t.DecBigram(normalized[i], normalized[i+1])
+		result = append(result, a, b)
+	}
+	return result, nil
+}
+
+// FillerMode — обработка заполнителей.
+type FillerMode int
+
+const (
+	FillersKeep     FillerMode = iota // при расшифровании заполнители остаются в тексте
+	FillersStrip                      // при расшифровании удаляются эвристически
+	FillersLossless                   // к шифртексту дописывается отметка, по которой расшифрование точно восстанавливает текст
+)
+
+func (m FillerMode) String() string {
+	switch m {
+	case FillersStrip:
+		return "удалять эвристически"
+	case FillersLossless:
+		return "без потерь (отметка в шифртексте)"
+	default:
+		return "оставлять"
+	}
+}
+
+// Отметка без потерь дописывается к шифртексту через пробел:
+//
+//	{pf x:3,10 j:0 а:5 ъ:2}
+//
+// Поле с ключом-заполнителем (x, а) перечисляет позиции заполнителей
+// в расшифрованном потоке букв, поле с объединённой буквой (j, ъ) — позиции
+// этой буквы в исходном потоке (после удаления заполнителей).
+// Пустые поля не записываются; «{pf}» означает, что восстанавливать нечего.
+const markPrefix = " {pf"
+
+// streamMarks — отметки одного потока букв.
+type streamMarks struct {
+	fillers []int
+	merged  map[rune][]int
+}
+
+// Playfair — биграммный шифр Плейфейра. По умолчанию латиница в таблице 5×5
+// (J = I), кириллица в таблице 4×8 (Ъ = Ь), заполнители 'x' и 'а'; размеры,
+// объединения и заполнители задаются таблицами Squares.
+type Playfair struct {
+	Lat     PlayfairTable
+	Cyr     PlayfairTable
+	Squares Squares
+	Fillers FillerMode
+}
+
+// NewPlayfair строит таблицы для обоих потоков по ключу
+// (нулевое значение squares — таблицы по умолчанию).
+func NewPlayfair(key PlayfairKey, squares Squares) (*Playfair, error) {
+	if key == "" {
+		return nil, fmt.Errorf("ключ не может быть пустым")
+	}
+	squares = squares.orDefault()
+	if err := squares.Validate(); err != nil {
+		return nil, err
+	}
+	return &Playfair{
+		Lat:     PlayfairTable{squares[0].Build(string(key))},
+		Cyr:     PlayfairTable{squares[1].Build(string(key))},
+		Squares: squares,
+	}, nil
+}
+
+// playfairStream — таблица и параметры потока одного алфавита.
+type playfairStream struct {
+	t  PlayfairTable
+	sq Square
+}
+
+func (p *Playfair) streams() [2]playfairStream {
+	sq := p.Squares.orDefault()
+	return [2]playfairStream{{p.Lat, sq[0]}, {p.Cyr, sq[1]}}
+}
+
+func (p *Playfair) Encrypt(text string) (string, error) { return runAll(p, text, true) }
+
+// Decrypt расшифровывает текст, обрабатывая заполнители согласно режиму Fillers.
+func (p *Playfair) Decrypt(text string) (string, error) {
+	if p.Fillers == FillersLossless {
+		return p.decryptMarked(text)
+	}
+	return runAll(p, text, false)
+}
+
+// Stream начинает потоковую обработку; незавершённая биграмма и позиции
+// для отметки без потерь переносятся между частями. Отметка стоит в конце
+// шифртекста, поэтому расшифрование в режиме FillersLossless накапливает
+// весь текст и выполняется при Flush.
+func (p *Playfair) Stream(encrypt bool) (Transformer, error) {
+	if !encrypt && p.Fillers == FillersLossless {
+		return &bufferedTransformer{process: p.decryptMarked}, nil
+	}
+	t := &playfairTransformer{p: p, encrypt: encrypt}
+	for i, s := range p.streams() {
+		t.states[i] = &playfairState{s: s, strip: p.Fillers == FillersStrip, marks: streamMarks{merged: make(map[rune][]int)}}
+	}
+	return t, nil
+}
+
+// decryptMarked расшифровывает текст по отметке без потерь в его конце;
+// без отметки заполнители удаляются эвристически.
+func (p *Playfair) decryptMarked(text string) (string, error) {
+	text, marks, hasMarks, err := cutMarks(text, p.streams())
+	if err != nil {
+		return "", err
+	}
+	tokens := p.Squares.Tokenize(text)
+	lat, cyr := Streams(tokens)
+
+	var results [2][]rune
+	for i, s := range p.streams() {
+		plain, err := decryptBigrams([2][]rune{lat, cyr}[i], s.t, s.sq.Norm)
+		if err != nil {
+			return "", err
+		}
+		if hasMarks {
+			plain, err = applyMarks(plain, marks[i])
+			if err != nil {
+				return "", err
+			}
+		} else {
+			plain = StripFillers(plain, s.sq)
+		}
+		results[i] = plain
+	}
+	return Restore(tokens, results[0], results[1]), nil
+}
+
+// playfairTransformer — потоковая обработка обоих алфавитов с расстановкой
+// результата по позициям букв исходного текста.
+type playfairTransformer struct {
+	p       *Playfair
+	encrypt bool
+	states  [2]*playfairState
+	out     restorer
+}
+
+func (t *playfairTransformer) Write(text string) (string, error) {
+	tokens := t.p.Squares.Tokenize(text)
+	t.out.tokens = append(t.out.tokens, tokens...)
+	for _, tok := range tokens {
+		var err error
+		switch {
+		case tok.IsLat:
+			err = t.states[0].letter(tok.R, t.encrypt, &t.out.lat)
+		case tok.IsCyr:
+			err = t.states[1].letter(tok.R, t.encrypt, &t.out.cyr)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	var sb strings.Builder
+	t.out.emit(&sb, false)
+	return sb.String(), nil
+}
+
+func (t *playfairTransformer) Flush() (string, error) {
+	if err := t.states[0].finish(t.encrypt, &t.out.lat); err != nil {
+		return "", err
+	}
+	if err := t.states[1].finish(t.encrypt, &t.out.cyr); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	t.out.emit(&sb, true)
+	if t.encrypt && t.p.Fillers == FillersLossless {
+		sb.WriteString(formatMarks(t.p.streams(), [2]streamMarks{t.states[0].marks, t.states[1].marks}))
+	}
+	return sb.String(), nil
+}
+
+// playfairState — потоковое состояние одного алфавита.
+type playfairState struct {
+	s     playfairStream
+	strip bool
+
+	pair  pairState   // шифрование: незавершённая биграмма открытого текста
+	read  int         // шифрование: прочитано букв открытого текста
+	n     int         // шифрование: длина подготовленного потока
+	marks streamMarks // шифрование: отметка без потерь
+
+	cipher  rune // расшифрование: первая буква незавершённой биграммы
+	hasHalf bool
+	held    [2]rune // расшифрование: последняя биграмма, вторая буква которой ещё не выведена
+	hasHeld bool
+}
+
+// letter обрабатывает очередную букву и дописывает готовые буквы результата в out.
+func (st *playfairState) letter(r rune, encrypt bool, out *[]rune) error {
+	if encrypt {
+		lr := unicode.ToLower(r)
+		if c := st.s.sq.Norm(lr); c != lr {
+			st.marks.merged[lr] = append(st.marks.merged[lr], st.read)
+		}
+		st.read++
+		pair, filled := st.pair.add(st.s.sq.Norm(r), st.s.sq)
+		st.encrypt(pair, filled, out)
+		return nil
+	}
+
+	c := st.s.sq.Norm(r)
+	if !st.hasHalf {
+		st.cipher, st.hasHalf = c, true
+		return nil
+	}
+	st.hasHalf = false
+	a, b := st.s.t.DecBigram(st.cipher, c)
+	if !st.strip {
+		*out = append(*out, a, b)
+		return nil
+	}
+	// вторая буква предыдущей биграммы — заполнитель, если разделяет одинаковые буквы
+	st.release(a, false, out)
+	*out = append(*out, a)
+	st.held, st.hasHeld = [2]rune{a, b}, true
+	return nil
+}
+
+// encrypt шифрует готовую биграмму и запоминает позицию заполнителя (filled).
+func (st *playfairState) encrypt(pair []rune, filled bool, out *[]rune) {
+	if pair == nil {
+		return
+	}
+	if filled {
+		st.marks.fillers = append(st.marks.fillers, st.n+1)
+	}
+	st.n += 2
+	a, b := st.s.t.EncBigram(pair[0], pair[1])
+	*out = append(*out, a, b)
+}
+
+// release выводит задержанную вторую букву биграммы, если она не заполнитель.
+func (st *playfairState) release(next rune, last bool, out *[]rune) {
+	if !st.hasHeld {
+		return
+	}
+	st.hasHeld = false
+	if !isFiller(st.held[0], st.held[1], next, last, st.s.sq) {
+		*out = append(*out, st.held[1])
+	}
+}
+
+// finish завершает поток алфавита.
+func (st *playfairState) finish(encrypt bool, out *[]rune) error {
+	if encrypt {
+		pair, filled := st.pair.finish(st.s.sq)
+		st.encrypt(pair, filled, out)
+		return nil
+	}
+	if st.hasHalf {
+		return fmt.Errorf("длина зашифрованного текста должна быть чётной (шифр Плейфейра работает с парами)")
+	}
+	st.release(0, true, out)
+	return nil
+}
+
+// bufferedTransformer накапливает весь текст и обрабатывает его при Flush.
+type bufferedTransformer struct {
+	text    strings.Builder
+	process func(string) (string, error)
+}
+
+func (t *bufferedTransformer) Write(text string) (string, error) {
+	t.text.WriteString(text)
+	return "", nil
+}
+
+func (t *bufferedTransformer) Flush() (string, error) { return t.process(t.text.String()) }
+
+// applyMarks удаляет заполнители по их позициям и возвращает объединённые буквы.
+func applyMarks(plain []rune, m streamMarks) ([]rune, error) {
+	drop := make(map[int]bool, len(m.fillers))
+	for _, i := range m.fillers {
+		if i >= len(plain) {
+			return nil, fmt.Errorf("отметка заполнителей не соответствует шифртексту")
+		}
+		drop[i] = true
+	}
+	out := make([]rune, 0, len(plain))
+	for i, r := range plain {
+		if !drop[i] {
+			out = append(out, r)
+		}
+	}
+	for r, positions := range m.merged {
+		for _, i := range positions {
+			if i >= len(out) {
+				return nil, fmt.Errorf("отметка заполнителей не соответствует шифртексту")
+			}
+			out[i] = r
+		}
+	}
+	return out, nil
+}
+
+// formatMarks записывает отметку без потерь (см. markPrefix).
+func formatMarks(streams [2]playfairStream, marks [2]streamMarks) string {
+	var sb strings.Builder
+	sb.WriteString(markPrefix)
+	field := func(key rune, positions []int) {
+		if len(positions) == 0 {
+			return
+		}
+		nums := make([]string, len(positions))
+		for i, n := range positions {
+			nums[i] = strconv.Itoa(n)
+		}
+		fmt.Fprintf(&sb, " %c:%s", key, strings.Join(nums, ","))
+	}
+	for i, s := range streams {
+		field(s.sq.Filler, marks[i].fillers)
+		keys := make([]rune, 0, len(marks[i].merged))
+		for r := range marks[i].merged {
+			keys = append(keys, r)
+		}
+		slices.Sort(keys)
+		for _, r := range keys {
+			field(r, marks[i].merged[r])
+		}
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// cutMarks отделяет отметку без потерь от конца текста и разбирает её.
+// Пробелы и переводы строк после отметки (например, дописанный редактором
+// перевод строки в конце файла) остаются в тексте.
+func cutMarks(text string, streams [2]playfairStream) (string, [2]streamMarks, bool, error) {
+	var marks [2]streamMarks
+	marked := strings.TrimRightFunc(text, unicode.IsSpace)
+	at := strings.LastIndex(marked, markPrefix)
+	if at < 0 || !strings.HasSuffix(marked, "}") {
+		return text, marks, false, nil
+	}
+	body := strings.TrimSuffix(marked[at+len(markPrefix):], "}")
+	if body != "" && !strings.HasPrefix(body, " ") {
+		return text, marks, false, nil
+	}
+
+	for _, f := range strings.Fields(body) {
+		key, list, ok := strings.Cut(f, ":")
+		k := []rune(key)
+		i := -1 // поток, к которому относится символ поля
+		if ok && len(k) == 1 {
+			i = slices.IndexFunc(streams[:], func(s playfairStream) bool { return s.sq.Has(k[0]) })
+		}
+		if i < 0 {
+			return "", marks, false, fmt.Errorf("неверное поле отметки %q", f)
+		}
+		var positions []int
+		for _, n := range strings.Split(list, ",") {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return "", marks, false, fmt.Errorf("неверное поле отметки %q", f)
+			}
+			positions = append(positions, v)
+		}
+
+		if k[0] == streams[i].sq.Filler {
+			marks[i].fillers = positions
+			continue
+		}
+		if marks[i].merged == nil {
+			marks[i].merged = make(map[rune][]int)
+		}
+		marks[i].merged[k[0]] = positions
+	}
+	return text[:at] + text[len(marked):], marks, true, nil
+}
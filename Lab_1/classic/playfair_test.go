@@ -0,0 +1,64 @@
+package classic
+
+import (
+	"strings"
+	"testing"
+)
+
+// Тексты с заполнителями: двойные буквы в паре, «x» и «j» в открытом тексте,
+// нечётная длина потоков, объединённые буквы кириллицы.
+var losslessTexts = []string{
+	"Hello, balloon!",
+	"Jazz jukebox: axe, xx, fix it",
+	"Съешь ещё этих мягких французских булок",
+	"Mixed текст: летопись jj и xxx\n",
+	"x",
+	"",
+}
+
+func newLosslessPlayfair(t *testing.T) *Playfair {
+	t.Helper()
+	p, err := NewPlayfair("monarchy", Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Fillers = FillersLossless
+	return p
+}
+
+// TestPlayfairLossless проверяет гарантию режима FillersLossless:
+// decrypt(encrypt(x)) == x, в том числе когда после отметки дописаны пробелы
+// или перевод строки (они остаются в конце расшифровки).
+func TestPlayfairLossless(t *testing.T) {
+	p := newLosslessPlayfair(t)
+	for _, text := range losslessTexts {
+		enc, err := p.Encrypt(text)
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		if !strings.Contains(enc, markPrefix) {
+			t.Fatalf("%q: нет отметки в шифртексте %q", text, enc)
+		}
+		for _, tail := range []string{"", "\n", " \r\n", "\t "} {
+			dec, err := p.Decrypt(enc + tail)
+			if err != nil {
+				t.Fatalf("%q + %q: %v", enc, tail, err)
+			}
+			if dec != text+tail {
+				t.Errorf("%q + %q: расшифровано %q, ожидается %q", enc, tail, dec, text+tail)
+			}
+		}
+	}
+}
+
+// TestCutMarksRejectsText проверяет, что текст, лишь похожий на отметку,
+// не принимается за неё.
+func TestCutMarksRejectsText(t *testing.T) {
+	p := newLosslessPlayfair(t)
+	for _, text := range []string{"ab {pfx}", "ab {pf", "ab}"} {
+		got, _, ok, err := cutMarks(text, p.streams())
+		if err != nil || ok || got != text {
+			t.Errorf("%q: отрезано %q, отметка %v, ошибка %v", text, got, ok, err)
+		}
+	}
+}
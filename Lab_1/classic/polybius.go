@@ -0,0 +1,293 @@
+package classic
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Шифры на основе квадрата Полибия: таблицы строятся по ключу так же, как
+// у Плейфейра (BuildTable), а буквы заменяются своими координатами.
+
+// buildSquares строит таблицы обоих потоков по ключу.
+func buildSquares(key PolybiusKey, squares Squares) [2]*Table {
+	return [2]*Table{squares[0].Build(string(key)), squares[1].Build(string(key))}
+}
+
+// Polybius — квадрат Полибия: каждая буква заменяется номерами своей строки
+// и столбца. Строки обеих таблиц нумеруются подряд цифрами 1–9 (сначала
+// первая таблица, затем вторая), поэтому по номеру строки видно алфавит буквы.
+type Polybius struct {
+	Tables  [2]*Table
+	Squares Squares
+}
+
+// NewPolybius строит таблицы по ключу (пустой ключ — таблицы по алфавиту;
+// нулевое значение squares — таблицы по умолчанию).
+func NewPolybius(key PolybiusKey, squares Squares) (*Polybius, error) {
+	squares = squares.orDefault()
+	if err := squares.Validate(); err != nil {
+		return nil, err
+	}
+	if rows := squares[0].Rows + squares[1].Rows; rows > 9 {
+		return nil, fmt.Errorf("квадрат Полибия: в таблицах %d строк, координаты допускают не больше 9", rows)
+	}
+	for _, s := range squares {
+		if s.Cols > 9 {
+			return nil, fmt.Errorf("квадрат Полибия: в таблице %s %d столбцов, координаты допускают не больше 9", s.Name, s.Cols)
+		}
+	}
+	return &Polybius{Tables: buildSquares(key, squares), Squares: squares}, nil
+}
+
+// RowLabel возвращает номер (1–9) строки i таблицы потока stream.
+func (p *Polybius) RowLabel(stream, i int) int {
+	if stream == 1 {
+		i += p.Tables[0].Rows
+	}
+	return i + 1
+}
+
+func (p *Polybius) Encrypt(text string) (string, error) { return runAll(p, text, true) }
+func (p *Polybius) Decrypt(text string) (string, error) { return runAll(p, text, false) }
+
+// Stream начинает потоковую обработку; при расшифровании незавершённая
+// координата переносится между частями.
+func (p *Polybius) Stream(encrypt bool) (Transformer, error) {
+	return &polybiusStream{p: p, encrypt: encrypt}, nil
+}
+
+type polybiusStream struct {
+	p       *Polybius
+	encrypt bool
+	pending rune // первая цифра незавершённой координаты
+}
+
+func (st *polybiusStream) Write(text string) (string, error) {
+	var sb strings.Builder
+	if st.encrypt {
+		for _, tok := range st.p.Squares.Tokenize(text) {
+			i := 0
+			switch {
+			case tok.IsLat:
+			case tok.IsCyr:
+				i = 1
+			case unicode.IsDigit(tok.R):
+				return "", fmt.Errorf("цифру %q вне таблиц нельзя отличить от координат", tok.R)
+			default:
+				sb.WriteRune(tok.R)
+				continue
+			}
+			pos := st.p.Tables[i].Pos[st.p.Squares[i].Norm(tok.R)]
+			fmt.Fprintf(&sb, "%d%d", st.p.RowLabel(i, pos[0]), pos[1]+1)
+		}
+		return sb.String(), nil
+	}
+
+	for _, r := range text {
+		switch {
+		case !unicode.IsDigit(r) && st.pending != 0:
+			return "", fmt.Errorf("координата %c не завершена перед %q", st.pending, r)
+		case !unicode.IsDigit(r):
+			sb.WriteRune(r)
+		case st.pending == 0:
+			st.pending = r
+		default:
+			c, err := st.p.cell(st.pending, r)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteRune(c)
+			st.pending = 0
+		}
+	}
+	return sb.String(), nil
+}
+
+func (st *polybiusStream) Flush() (string, error) {
+	if st.pending != 0 {
+		return "", fmt.Errorf("координата %c не завершена в конце текста", st.pending)
+	}
+	return "", nil
+}
+
+// cell возвращает букву по цифрам строки и столбца.
+func (p *Polybius) cell(row, col rune) (rune, error) {
+	i, j := int(row-'1'), int(col-'1')
+	for _, t := range p.Tables {
+		if i >= 0 && i < t.Rows && j >= 0 && j < t.Cols {
+			return t.Grid[i][j], nil
+		}
+		i -= t.Rows
+	}
+	return 0, fmt.Errorf("нет ячейки с координатами %c%c", row, col)
+}
+
+// Fractionation — шифр дробления: буквы блока (периода) раскладываются на
+// координаты, координаты выписываются построчно (все первые, затем все
+// вторые…) и снова собираются в буквы. Бифид использует строку и столбец
+// квадратной таблицы, Трифид — слой, строку и столбец куба 3×3×3 (таблица
+// 3×9, в которой каждые три столбца — слой).
+type Fractionation struct {
+	Tables  [2]*Table
+	Squares Squares
+	Period  int // длина блока; 0 — всё сообщение одним блоком
+	Cube    bool
+}
+
+// NewBifid создаёт шифр Бифид с периодом period (0 — без деления на блоки;
+// нулевое значение squares — BifidSquares). Таблицы должны быть квадратными.
+func NewBifid(key PolybiusKey, period int, squares Squares) (*Fractionation, error) {
+	squares = squares.Or(BifidSquares)
+	for _, s := range squares {
+		if s.Rows != s.Cols {
+			return nil, fmt.Errorf("Бифид: таблица %s %d×%d не квадратная", s.Name, s.Rows, s.Cols)
+		}
+	}
+	return newFractionation(key, period, squares, false)
+}
+
+// NewTrifid создаёт шифр Трифид с периодом period (0 — без деления на блоки;
+// нулевое значение squares — TrifidSquares). Таблицы должны быть 3×9.
+func NewTrifid(key PolybiusKey, period int, squares Squares) (*Fractionation, error) {
+	squares = squares.Or(TrifidSquares)
+	for _, s := range squares {
+		if s.Rows != 3 || s.Cols != 9 {
+			return nil, fmt.Errorf("Трифид: таблица %s %d×%d, нужна 3×9 (куб 3×3×3)", s.Name, s.Rows, s.Cols)
+		}
+	}
+	return newFractionation(key, period, squares, true)
+}
+
+func newFractionation(key PolybiusKey, period int, squares Squares, cube bool) (*Fractionation, error) {
+	if period < 0 {
+		return nil, fmt.Errorf("период не может быть отрицательным")
+	}
+	if err := squares.Validate(); err != nil {
+		return nil, err
+	}
+	return &Fractionation{Tables: buildSquares(key, squares), Squares: squares, Period: period, Cube: cube}, nil
+}
+
+// Name возвращает название шифра.
+func (f *Fractionation) Name() string {
+	if f.Cube {
+		return "Трифид"
+	}
+	return "Бифид"
+}
+
+// coords раскладывает букву таблицы t на координаты.
+func (f *Fractionation) coords(t *Table, r rune) []int {
+	p := t.Pos[r]
+	if f.Cube {
+		return []int{p[0], p[1] / 3, p[1] % 3}
+	}
+	return []int{p[0], p[1]}
+}
+
+// letter собирает букву таблицы t из координат.
+func (f *Fractionation) letter(t *Table, c []int) rune {
+	if f.Cube {
+		return t.Grid[c[0]][c[1]*3+c[2]]
+	}
+	return t.Grid[c[0]][c[1]]
+}
+
+// block шифрует или дешифрует блок нормализованных букв таблицы t.
+func (f *Fractionation) block(t *Table, letters []rune, encrypt bool) []rune {
+	n := len(letters)
+	d := 2
+	if f.Cube {
+		d = 3
+	}
+	seq := make([]int, 0, n*d)
+	out := make([]rune, n)
+	if encrypt {
+		coords := make([][]int, n)
+		for i, r := range letters {
+			coords[i] = f.coords(t, r)
+		}
+		for j := range d {
+			for i := range n {
+				seq = append(seq, coords[i][j])
+			}
+		}
+		for i := range n {
+			out[i] = f.letter(t, seq[i*d:(i+1)*d])
+		}
+		return out
+	}
+	for _, r := range letters {
+		seq = append(seq, f.coords(t, r)...)
+	}
+	c := make([]int, d)
+	for i := range n {
+		for j := range d {
+			c[j] = seq[j*n+i]
+		}
+		out[i] = f.letter(t, c)
+	}
+	return out
+}
+
+func (f *Fractionation) Encrypt(text string) (string, error) { return runAll(f, text, true) }
+func (f *Fractionation) Decrypt(text string) (string, error) { return runAll(f, text, false) }
+
+// Stream начинает потоковую обработку: буквы каждого алфавита копятся до
+// полного блока, последний неполный блок обрабатывается при Flush.
+// Регистр и не-буквы восстанавливаются по токенам.
+func (f *Fractionation) Stream(encrypt bool) (Transformer, error) {
+	return &fractionStream{f: f, encrypt: encrypt}, nil
+}
+
+type fractionStream struct {
+	f       *Fractionation
+	encrypt bool
+	pending [2][]rune
+	out     restorer
+}
+
+// process обрабатывает накопленные буквы потока i.
+func (st *fractionStream) process(i int) {
+	res := st.f.block(st.f.Tables[i], st.pending[i], st.encrypt)
+	st.pending[i] = st.pending[i][:0]
+	if i == 0 {
+		st.out.lat = append(st.out.lat, res...)
+	} else {
+		st.out.cyr = append(st.out.cyr, res...)
+	}
+}
+
+func (st *fractionStream) Write(text string) (string, error) {
+	tokens := st.f.Squares.Tokenize(text)
+	st.out.tokens = append(st.out.tokens, tokens...)
+	for _, tok := range tokens {
+		i := 0
+		switch {
+		case tok.IsLat:
+		case tok.IsCyr:
+			i = 1
+		default:
+			continue
+		}
+		st.pending[i] = append(st.pending[i], st.f.Squares[i].Norm(tok.R))
+		if len(st.pending[i]) == st.f.Period {
+			st.process(i)
+		}
+	}
+	var sb strings.Builder
+	st.out.emit(&sb, false)
+	return sb.String(), nil
+}
+
+func (st *fractionStream) Flush() (string, error) {
+	for i := range st.pending {
+		if len(st.pending[i]) > 0 {
+			st.process(i)
+		}
+	}
+	var sb strings.Builder
+	st.out.emit(&sb, true)
+	return sb.String(), nil
+}
@@ -0,0 +1,86 @@
+package classic
+
+import "testing"
+
+// TestPolybiusVector — классический квадрат 5×5 без ключа: HELLO → 23 15 31 31 34.
+func TestPolybiusVector(t *testing.T) {
+	p, err := NewPolybius("", Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := p.Encrypt("hello"); got != "2315313134" {
+		t.Errorf("шифртекст %q, ожидается 2315313134", got)
+	}
+	if _, err := p.Encrypt("room 101"); err == nil {
+		t.Error("цифры вне таблиц приняты")
+	}
+}
+
+// TestFractionationVectors — примеры из литературы: Бифид с таблицей
+// BGWKZ QPNDS IOAXE FCLUM THYVR одним блоком и Трифид с ключом
+// FELIX MARIE DELASTELLE и периодом 5.
+func TestFractionationVectors(t *testing.T) {
+	bifid, err := NewBifid("BGWKZQPNDSIOAXEFCLUMTHYVR", 0, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trifid, err := NewTrifid("FELIX MARIE DELASTELLE", 5, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name          string
+		c             Cipher
+		plain, cipher string
+	}{
+		{"Бифид", bifid, "FLEEATONCE", "UAEOLWRINS"},
+		{"Трифид", trifid, "aidetoilecieltaidera", "fmjfvoissuftfpufeqqc"},
+	} {
+		if got, _ := tc.c.Encrypt(tc.plain); got != tc.cipher {
+			t.Errorf("%s: шифртекст %q, ожидается %q", tc.name, got, tc.cipher)
+		}
+		if got, _ := tc.c.Decrypt(tc.cipher); got != tc.plain {
+			t.Errorf("%s: расшифровано %q, ожидается %q", tc.name, got, tc.plain)
+		}
+	}
+}
+
+// TestPolybiusFamilyRoundTrip проверяет расшифрование на обоих потоках:
+// J заменяется на I, квадрат Полибия теряет регистр, блоки Бифида и Трифида
+// не совпадают с границами потоков.
+func TestPolybiusFamilyRoundTrip(t *testing.T) {
+	const text = "Jolly good, привет, мир!"
+	polybius, err := NewPolybius("key ключ", Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bifid, err := NewBifid("key ключ", 5, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trifid, err := NewTrifid("key ключ", 4, Squares{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		c    Cipher
+		want string
+	}{
+		{"квадрат Полибия", polybius, "iolly good, привет, мир!"},
+		{"Бифид", bifid, "Iolly good, привет, мир!"},
+		{"Трифид", trifid, text},
+	} {
+		enc, err := tc.c.Encrypt(text)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		dec, err := tc.c.Decrypt(enc)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if dec != tc.want {
+			t.Errorf("%s: расшифровано %q, ожидается %q", tc.name, dec, tc.want)
+		}
+	}
+}
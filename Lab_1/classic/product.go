@@ -0,0 +1,68 @@
+package classic
+
+import "fmt"
+
+// Product — составной шифр: при шифровании шифры применяются по порядку
+// (например, замена, затем перестановка), при расшифровании — обратные
+// операции в обратном порядке.
+type Product []Streamer
+
+// NewProduct проверяет и собирает цепочку шифров.
+func NewProduct(stages ...Streamer) (Product, error) {
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("цепочка шифров пуста")
+	}
+	return Product(stages), nil
+}
+
+func (p Product) Encrypt(text string) (string, error) { return runAll(p, text, true) }
+func (p Product) Decrypt(text string) (string, error) { return runAll(p, text, false) }
+
+// Stream соединяет потоки шифров цепочки: выход каждого передаётся на вход
+// следующего, при Flush остаток каждого шифра проходит через все последующие.
+func (p Product) Stream(encrypt bool) (Transformer, error) {
+	stages := make([]Transformer, len(p))
+	for i := range p {
+		k := i
+		if !encrypt {
+			k = len(p) - 1 - i
+		}
+		t, err := p[k].Stream(encrypt)
+		if err != nil {
+			return nil, err
+		}
+		stages[i] = t
+	}
+	return productStream(stages), nil
+}
+
+type productStream []Transformer
+
+func (ps productStream) Write(text string) (string, error) {
+	var err error
+	for _, t := range ps {
+		if text, err = t.Write(text); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+func (ps productStream) Flush() (string, error) {
+	var out string
+	for i, t := range ps {
+		if i > 0 {
+			w, err := t.Write(out)
+			if err != nil {
+				return "", err
+			}
+			out = w
+		}
+		tail, err := t.Flush()
+		if err != nil {
+			return "", err
+		}
+		out += tail
+	}
+	return out, nil
+}
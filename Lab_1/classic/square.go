@@ -0,0 +1,341 @@
+package classic
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Square — таблица биграммного шифра (Плейфейр, двойной квадрат): размеры,
+// символы таблицы и объединения символов, которым в таблице нет места.
+type Square struct {
+	Name       string
+	Rows, Cols int
+	Letters    string        // символы таблицы в порядке заполнения после ключа
+	Merge      map[rune]rune // символ вне таблицы → символ таблицы (j → i)
+	Filler     rune          // заполнитель биграмм
+	AltFiller  rune          // заполнитель после самого заполнителя
+}
+
+// Squares — таблицы двух потоков: первый (латиница, в 6×6 — и цифры)
+// и второй (кириллица). Символ относится к первой таблице, в которой он есть.
+type Squares [2]Square
+
+// squarePreset — встроенная таблица: base — все символы потока,
+// merge — объединения по умолчанию.
+//
+// Объединения ё=е, й=и, ъ=ь, щ=ш, э=е почти не мешают читать расшифровку,
+// а ю=у и ф=в в таблицах 6×6 и 3×3×3 меняют слова («юг» → «уг», «флаг» →
+// «влаг»): там кириллице с цифрами не хватает места. Описания таблиц
+// называют такие объединения, чтобы расшифровка не удивляла.
+type squarePreset struct {
+	name       string
+	title      string
+	rows, cols int
+	base       string
+	merge      string
+}
+
+var squarePresets = []squarePreset{
+	{"latin5x5", "латиница 5×5", 5, 5, LatinAlphabet, "j=i"},
+	{"latin6x6", "латиница и цифры 6×6", 6, 6, LatinAlphabet + "0123456789", ""},
+	{"cyrillic4x8", "кириллица 4×8", 4, 8, CyrillicAlphabet, "ъ=ь"},
+	{"cyrillic5x6", "кириллица 5×6", 5, 6, CyrillicAlphabet, "ё=е й=и ъ=ь"},
+	{"cyrillic6x6", "кириллица и цифры 6×6, Ю читается как У, Ф — как В", 6, 6, CyrillicAlphabet + "0123456789", "ё=е й=и ъ=ь щ=ш э=е ю=у ф=в"},
+	{"latin3x9", "латиница и «+», куб 3×3×3", 3, 9, LatinAlphabet + "+", ""},
+	{"cyrillic3x9", "кириллица, куб 3×3×3, Ю читается как У", 3, 9, CyrillicAlphabet, "ё=е й=и ъ=ь щ=ш э=е ю=у"},
+}
+
+// DefaultSquares — латиница 5×5 (J = I) и кириллица 4×8 (Ъ = Ь).
+var DefaultSquares = Squares{mustSquare("latin5x5"), mustSquare("cyrillic4x8")}
+
+// Таблицы по умолчанию шифров дробления: Бифиду нужны квадратные таблицы,
+// Трифиду — 27 символов куба 3×3×3, ADFGVX — не больше 6 строк и столбцов.
+// В кириллических таблицах Бифида и Трифида Ю расшифровывается как У,
+// а у Бифида ещё и Ф — как В.
+var (
+	BifidSquares  = Squares{mustSquare("latin5x5"), mustSquare("cyrillic6x6")}
+	TrifidSquares = Squares{mustSquare("latin3x9"), mustSquare("cyrillic3x9")}
+	ADFGVXSquares = Squares{mustSquare("latin6x6"), mustSquare("cyrillic5x6")}
+)
+
+func mustSquare(spec string) Square {
+	s, err := ParseSquare(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// SquarePresets возвращает имена и описания встроенных таблиц.
+func SquarePresets() [][2]string {
+	out := make([][2]string, len(squarePresets))
+	for i, p := range squarePresets {
+		out[i] = [2]string{p.name, p.title}
+	}
+	return out
+}
+
+// NewSquare создаёт таблицу rows×cols из символов base; символы — ключи merge
+// (их может и не быть в base) заменяются своими значениями и в таблицу не
+// входят. Оставшихся символов должно быть ровно rows·cols.
+func NewSquare(name string, rows, cols int, base string, merge map[rune]rune) (Square, error) {
+	if rows < 2 || cols < 2 {
+		return Square{}, fmt.Errorf("таблица %s: нужно хотя бы 2×2", name)
+	}
+	all := []rune(strings.ToLower(base))
+	for i, r := range all {
+		if slices.Contains(all[:i], r) {
+			return Square{}, fmt.Errorf("таблица %s: символ %q повторяется", name, r)
+		}
+	}
+	var letters []rune
+	for _, r := range all {
+		if _, ok := merge[r]; !ok {
+			letters = append(letters, r)
+		}
+	}
+	for from, to := range merge {
+		if !slices.Contains(letters, to) {
+			return Square{}, fmt.Errorf("таблица %s: символ %q объединяется с %q, которого нет в таблице", name, from, to)
+		}
+	}
+	if len(letters) != rows*cols {
+		return Square{}, fmt.Errorf("таблица %s: %d символов не заполняют %d×%d = %d ячеек",
+			name, len(letters), rows, cols, rows*cols)
+	}
+
+	s := Square{Name: name, Rows: rows, Cols: cols, Letters: string(letters), Merge: merge}
+	s.Filler = s.pick("xа", letters[len(letters)-1])
+	s.AltFiller = s.pick("qйzя", letters[0])
+	if s.AltFiller == s.Filler {
+		s.AltFiller = letters[1]
+	}
+	return s, nil
+}
+
+// pick возвращает первый из предпочтительных символов, который есть в таблице.
+func (s Square) pick(preferred string, fallback rune) rune {
+	for _, r := range preferred {
+		if strings.ContainsRune(s.Letters, r) {
+			return r
+		}
+	}
+	return fallback
+}
+
+// ParseSquare разбирает описание таблицы: имя встроенной таблицы (latin5x5,
+// latin6x6, latin3x9, cyrillic4x8, cyrillic5x6, cyrillic6x6, cyrillic3x9)
+// или размер и символы («6x6 abc…»), за которыми через пробел идут
+// объединения вида «j=i».
+// Объединения после имени встроенной таблицы заменяют её собственные:
+// «latin5x5 q=k» — таблица 5×5 без Q вместо таблицы без J.
+func ParseSquare(spec string) (Square, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Square{}, fmt.Errorf("пустое описание таблицы")
+	}
+	name := strings.ToLower(fields[0])
+	var rows, cols int
+	var base string
+	rules := fields[1:]
+	if i := slices.IndexFunc(squarePresets, func(p squarePreset) bool { return p.name == name }); i >= 0 {
+		p := squarePresets[i]
+		rows, cols, base = p.rows, p.cols, p.base
+		if len(rules) == 0 {
+			rules = strings.Fields(p.merge)
+		}
+	} else {
+		r, c, ok := strings.Cut(strings.ReplaceAll(name, "×", "x"), "x")
+		var err1, err2 error
+		rows, err1 = strconv.Atoi(r)
+		cols, err2 = strconv.Atoi(c)
+		if !ok || err1 != nil || err2 != nil || len(rules) == 0 {
+			return Square{}, fmt.Errorf("таблица %q: ожидается имя встроенной таблицы или «строки×столбцы символы»", spec)
+		}
+		base, rules = rules[0], rules[1:]
+	}
+
+	merge := make(map[rune]rune)
+	for _, rule := range rules {
+		from, to, ok := strings.Cut(strings.ToLower(rule), "=")
+		f, t := []rune(from), []rune(to)
+		if !ok || len(f) != 1 || len(t) != 1 {
+			return Square{}, fmt.Errorf("таблица %s: объединение %q, ожидается вида «j=i»", name, rule)
+		}
+		merge[f[0]] = t[0]
+	}
+	return NewSquare(name, rows, cols, base, merge)
+}
+
+// Norm приводит символ к таблице: нижний регистр и объединения.
+func (s Square) Norm(r rune) rune {
+	r = unicode.ToLower(r)
+	if m, ok := s.Merge[r]; ok {
+		return m
+	}
+	return r
+}
+
+// Has сообщает, относится ли символ к таблице (с учётом объединений).
+func (s Square) Has(r rune) bool {
+	return strings.ContainsRune(s.Letters, s.Norm(r))
+}
+
+// Build строит таблицу по ключу (см. BuildTable).
+func (s Square) Build(key string) *Table {
+	return BuildTable(key, s.Rows, s.Cols, s.Letters, s.Norm)
+}
+
+// String описывает таблицу: имя, размер и объединения.
+func (s Square) String() string {
+	keys := mapKeys(s.Merge)
+	slices.Sort(keys)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%d×%d", s.Name, s.Rows, s.Cols)
+	for _, r := range keys {
+		fmt.Fprintf(&sb, ", %c = %c", unicode.ToUpper(r), unicode.ToUpper(s.Merge[r]))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// orDefault подставляет таблицы по умолчанию вместо незаданных.
+func (sq Squares) orDefault() Squares { return sq.Or(DefaultSquares) }
+
+// Or подставляет таблицы def вместо незаданных (нулевых).
+func (sq Squares) Or(def Squares) Squares {
+	for i := range sq {
+		if sq[i].Rows == 0 {
+			sq[i] = def[i]
+		}
+	}
+	return sq
+}
+
+// Validate проверяет, что таблицы не делят символы: иначе символ шифртекста
+// из второй таблицы при расшифровании попал бы в поток первой.
+func (sq Squares) Validate() error {
+	sq = sq.orDefault()
+	for _, r := range sq[1].Letters + string(mapKeys(sq[1].Merge)) {
+		if sq[0].Has(r) {
+			return fmt.Errorf("таблицы %s и %s содержат общий символ %q", sq[0].Name, sq[1].Name, r)
+		}
+	}
+	return nil
+}
+
+func mapKeys(m map[rune]rune) []rune {
+	keys := make([]rune, 0, len(m))
+	for r := range m {
+		keys = append(keys, r)
+	}
+	return keys
+}
+
+// Tokenize разбирает текст на токены, относя символы к потокам таблиц:
+// IsLat — первая таблица, IsCyr — вторая.
+func (sq Squares) Tokenize(text string) []Token {
+	sq = sq.orDefault()
+	runes := []rune(text)
+	tokens := make([]Token, len(runes))
+	for i, r := range runes {
+		lat := sq[0].Has(r)
+		tokens[i] = Token{
+			R:     r,
+			IsLat: lat,
+			IsCyr: !lat && sq[1].Has(r),
+			Upper: unicode.IsUpper(r),
+		}
+	}
+	return tokens
+}
+
+// String перечисляет таблицы обоих потоков.
+func (sq Squares) String() string {
+	sq = sq.orDefault()
+	return sq[0].String() + ", " + sq[1].String()
+}
+
+// SquareFlags регистрирует флаги -lat и -cyr (описания таблиц, см. ParseSquare).
+// Возвращённая функция после fs.Parse собирает и проверяет пару таблиц.
+func SquareFlags(fs *flag.FlagSet) func() (Squares, error) {
+	lat := fs.String("lat", "latin5x5", "таблица первого потока: latin5x5, latin6x6 или «RxC символы объединения»")
+	cyr := fs.String("cyr", "cyrillic4x8", "таблица второго потока: cyrillic4x8, cyrillic5x6, cyrillic6x6 (Ю = У, Ф = В) или «RxC символы объединения»")
+	return func() (Squares, error) {
+		sq, err := parseSquares(*lat, *cyr)
+		if err != nil {
+			return sq, err
+		}
+		return sq, sq.Validate()
+	}
+}
+
+// OptionalSquareFlags регистрирует флаги -lat и -cyr без значений по умолчанию:
+// незаданная таблица остаётся нулевой, и её выбирает шифр (см. Squares.Or).
+// Пара таблиц проверяется при создании шифра.
+func OptionalSquareFlags(fs *flag.FlagSet) func() (Squares, error) {
+	lat := fs.String("lat", "", "таблица первого потока (по умолчанию — таблица шифра)")
+	cyr := fs.String("cyr", "", "таблица второго потока (по умолчанию — таблица шифра; у Бифида и Трифида Ю = У, у Бифида и Ф = В)")
+	return func() (Squares, error) { return parseSquares(*lat, *cyr) }
+}
+
+// parseSquares разбирает описания таблиц обоих потоков; пустое описание
+// оставляет таблицу нулевой.
+func parseSquares(specs ...string) (Squares, error) {
+	var sq Squares
+	for i, spec := range specs {
+		if spec == "" {
+			continue
+		}
+		s, err := ParseSquare(spec)
+		if err != nil {
+			return sq, err
+		}
+		sq[i] = s
+	}
+	return sq, nil
+}
+
+// ChooseSquares — диалог выбора таблиц; при ошибке таблицы не меняются.
+func ChooseSquares(current Squares) (Squares, error) {
+	sq := current.orDefault()
+	for i, title := range []string{"Таблица первого потока (латиница)", "Таблица второго потока (кириллица)"} {
+		fmt.Println(title+", сейчас", sq[i].String()+":")
+		var names []string
+		for _, p := range SquarePresets() {
+			if strings.HasPrefix(p[0], []string{"latin", "cyrillic"}[i]) {
+				names = append(names, p[0])
+				fmt.Printf("  %d — %s (%s)\n", len(names), p[1], p[0])
+			}
+		}
+		fmt.Printf("  %d — своя таблица или свои объединения\n", len(names)+1)
+		fmt.Println("  пусто — оставить")
+		choice := strings.TrimSpace(ReadLine(": "))
+		if choice == "" {
+			continue
+		}
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(names)+1 {
+			return current, fmt.Errorf("неверный выбор")
+		}
+		spec := ""
+		if n <= len(names) {
+			spec = names[n-1]
+		} else {
+			spec = ReadLine("Описание (например «latin5x5 q=k» или «5x6 абвгдежзиклмнопрстуфхцчшщыьэюя ё=е й=и ъ=ь»): ")
+		}
+		s, err := ParseSquare(spec)
+		if err != nil {
+			return current, err
+		}
+		sq[i] = s
+	}
+	if err := sq.Validate(); err != nil {
+		return current, err
+	}
+	return sq, nil
+}
@@ -0,0 +1,34 @@
+package classic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSquarePresets проверяет, что встроенные таблицы строятся и каждый
+// символ потока либо стоит в таблице, либо объединяется с символом таблицы.
+func TestSquarePresets(t *testing.T) {
+	for _, p := range squarePresets {
+		sq, err := ParseSquare(p.name)
+		if err != nil {
+			t.Fatalf("%s: %v", p.name, err)
+		}
+		for _, r := range p.base {
+			if !sq.Has(r) || !strings.ContainsRune(sq.Letters, sq.Norm(r)) {
+				t.Errorf("%s: символ %q теряется (→ %q)", p.name, r, sq.Norm(r))
+			}
+		}
+	}
+}
+
+// TestSquareDigits проверяет, что таблицы с цифрами различают все десять цифр.
+func TestSquareDigits(t *testing.T) {
+	for _, name := range []string{"latin6x6", "cyrillic6x6"} {
+		sq := mustSquare(name)
+		for _, d := range "0123456789" {
+			if sq.Norm(d) != d {
+				t.Errorf("%s: цифра %q заменяется на %q", name, d, sq.Norm(d))
+			}
+		}
+	}
+}
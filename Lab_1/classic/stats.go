@@ -0,0 +1,160 @@
+package classic
+
+import (
+	"math"
+	"slices"
+	"unicode"
+)
+
+// Частоты букв (в процентах) в порядке LatinAlphabet и CyrillicAlphabet.
+var EnglishFreq = []float64{
+	8.167, 1.492, 2.782, 4.253, 12.702, 2.228, 2.015, 6.094, 6.966, 0.153, 0.772, 4.025, 2.406,
+	6.749, 7.507, 1.929, 0.095, 5.987, 6.327, 9.056, 2.758, 0.978, 2.360, 0.150, 1.974, 0.074,
+}
+
+var RussianFreq = []float64{
+	8.01, 1.59, 4.54, 1.70, 2.98, 8.45, 0.04, 0.94, 1.65, 7.35, 1.21, 3.49, 4.40, 3.21, 6.70, 10.97, 2.81,
+	4.73, 5.47, 6.26, 2.62, 0.26, 0.97, 0.48, 1.44, 0.73, 0.36, 0.04, 1.90, 1.74, 0.32, 0.64, 2.01,
+}
+
+// ExpectedIC — индекс совпадений открытого текста: сумма квадратов вероятностей букв.
+func ExpectedIC(freq []float64) float64 {
+	var sum float64
+	for _, f := range freq {
+		sum += (f / 100) * (f / 100)
+	}
+	return sum
+}
+
+// Stream — поток букв одного алфавита в виде индексов.
+type Stream struct {
+	Alphabet []rune
+	Letters  []int
+}
+
+// NewStream оставляет в тексте буквы алфавита (без учёта регистра).
+func NewStream(text []rune, alphabet string) *Stream {
+	s := &Stream{Alphabet: []rune(alphabet)}
+	for _, r := range text {
+		if i := IndexOf(s.Alphabet, unicode.ToLower(r)); i >= 0 {
+			s.Letters = append(s.Letters, i)
+		}
+	}
+	return s
+}
+
+// Counts — число вхождений каждой буквы алфавита.
+func (s *Stream) Counts() []int {
+	counts := make([]int, len(s.Alphabet))
+	for _, l := range s.Letters {
+		counts[l]++
+	}
+	return counts
+}
+
+// Bigrams — число вхождений каждой биграммы (с перекрытием), индекс a·k + b.
+func (s *Stream) Bigrams() []int {
+	k := len(s.Alphabet)
+	counts := make([]int, k*k)
+	for i := 0; i+1 < len(s.Letters); i++ {
+		counts[s.Letters[i]*k+s.Letters[i+1]]++
+	}
+	return counts
+}
+
+// IC — индекс совпадений: вероятность совпадения двух случайно выбранных букв.
+func (s *Stream) IC() float64 {
+	n := len(s.Letters)
+	if n < 2 {
+		return 0
+	}
+	var sum int
+	for _, c := range s.Counts() {
+		sum += c * (c - 1)
+	}
+	return float64(sum) / float64(n*(n-1))
+}
+
+// Entropy — энтропия Шеннона распределения букв (бит на букву).
+func (s *Stream) Entropy() float64 {
+	n := float64(len(s.Letters))
+	var h float64
+	for _, c := range s.Counts() {
+		if c > 0 {
+			p := float64(c) / n
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// Gram переводит n букв потока, начиная с позиции i, в строку.
+func (s *Stream) Gram(i, n int) string {
+	out := make([]rune, n)
+	for j := range out {
+		out[j] = s.Alphabet[s.Letters[i+j]]
+	}
+	return string(out)
+}
+
+// Repeat — повторяющаяся n-грамма: позиции вхождений в потоке и расстояния между соседними.
+type Repeat struct {
+	Gram      string
+	Positions []int
+	Distances []int
+}
+
+// Repeats находит n-граммы, встречающиеся в потоке больше одного раза,
+// в порядке первого вхождения.
+func (s *Stream) Repeats(n int) []Repeat {
+	positions := make(map[string][]int)
+	var order []string
+	for i := 0; i+n <= len(s.Letters); i++ {
+		g := s.Gram(i, n)
+		if positions[g] == nil {
+			order = append(order, g)
+		}
+		positions[g] = append(positions[g], i)
+	}
+	var repeats []Repeat
+	for _, g := range order {
+		pos := positions[g]
+		if len(pos) < 2 {
+			continue
+		}
+		r := Repeat{Gram: g, Positions: pos}
+		for i := 1; i < len(pos); i++ {
+			r.Distances = append(r.Distances, pos[i]-pos[i-1])
+		}
+		repeats = append(repeats, r)
+	}
+	return repeats
+}
+
+// ChiSquared сравнивает распределение букв потока с частотами языка freq (в процентах);
+// ожидаемые частоты ограничены снизу minFreq, чтобы редкие буквы не доминировали.
+func (s *Stream) ChiSquared(freq []float64, minFreq float64) float64 {
+	n := float64(len(s.Letters))
+	var chi float64
+	for i, c := range s.Counts() {
+		exp := n * math.Max(freq[i], minFreq) / 100
+		d := float64(c) - exp
+		chi += d * d / exp
+	}
+	return chi
+}
+
+// TopIndices возвращает индексы ненулевых счётчиков по убыванию (не больше limit, 0 — все).
+func TopIndices(counts []int, limit int) []int {
+	var idx []int
+	for i, c := range counts {
+		if c > 0 {
+			idx = append(idx, i)
+		}
+	}
+	slices.SortStableFunc(idx, func(a, b int) int { return counts[b] - counts[a] })
+	if limit > 0 && len(idx) > limit {
+		idx = idx[:limit]
+	}
+	return idx
+}
@@ -0,0 +1,57 @@
+package classic
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestStreamIC проверяет индекс совпадений и энтропию на коротких потоках,
+// посчитанных вручную.
+func TestStreamIC(t *testing.T) {
+	tests := []struct {
+		text    string
+		ic      float64
+		entropy float64
+	}{
+		{"aabb", 4.0 / 12, 1},
+		{"abcd", 0, 2},
+		{"aaaa", 1, 0},
+		{"AAbb, cc!", 6.0 / 30, math.Log2(3)},
+		{"а", 0, 0},
+	}
+	for _, tt := range tests {
+		s := NewStream([]rune(tt.text), LatinAlphabet)
+		if got := s.IC(); math.Abs(got-tt.ic) > 1e-12 {
+			t.Errorf("%q: индекс совпадений %.4f, ожидается %.4f", tt.text, got, tt.ic)
+		}
+		if got := s.Entropy(); math.Abs(got-tt.entropy) > 1e-12 {
+			t.Errorf("%q: энтропия %.4f, ожидается %.4f", tt.text, got, tt.entropy)
+		}
+	}
+}
+
+// TestStreamRepeats проверяет позиции повторов и расстояния между ними.
+func TestStreamRepeats(t *testing.T) {
+	s := NewStream([]rune("abc x abc y abc z bcx"), LatinAlphabet)
+	got := s.Repeats(3)
+	want := []Repeat{
+		{Gram: "abc", Positions: []int{0, 4, 8}, Distances: []int{4, 4}},
+		{Gram: "bcx", Positions: []int{1, 12}, Distances: []int{11}},
+	}
+	if !slices.EqualFunc(got, want, func(a, b Repeat) bool {
+		return a.Gram == b.Gram && slices.Equal(a.Positions, b.Positions) && slices.Equal(a.Distances, b.Distances)
+	}) {
+		t.Errorf("повторы %+v, ожидается %+v", got, want)
+	}
+}
+
+// TestExpectedIC сверяет индекс совпадений языков с табличными значениями.
+func TestExpectedIC(t *testing.T) {
+	if got := ExpectedIC(EnglishFreq); math.Abs(got-0.0655) > 0.001 {
+		t.Errorf("английский: %.4f, ожидается ≈ 0.0655", got)
+	}
+	if got := ExpectedIC(RussianFreq); math.Abs(got-0.0553) > 0.001 {
+		t.Errorf("русский: %.4f, ожидается ≈ 0.0553", got)
+	}
+}
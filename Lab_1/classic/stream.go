@@ -0,0 +1,202 @@
+package classic
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Потоковая обработка: текст подаётся шифру частями, а состояние (позиция
+// ключа, незавершённая биграмма) переносится через границы частей, поэтому
+// результат совпадает с обработкой всего текста целиком.
+
+// Transformer — состояние потоковой обработки текста шифром.
+type Transformer interface {
+	// Write обрабатывает очередную часть текста и возвращает готовую часть
+	// результата (часть букв может быть задержана до следующего вызова).
+	Write(chunk string) (string, error)
+	// Flush завершает поток и возвращает остаток результата.
+	Flush() (string, error)
+}
+
+// Streamer — шифр, умеющий обрабатывать текст потоком.
+type Streamer interface {
+	Cipher
+	Stream(encrypt bool) (Transformer, error)
+}
+
+// chunkSize — размер читаемой за раз части входа.
+const chunkSize = 64 << 10
+
+// runAll обрабатывает весь текст одной частью.
+func runAll(c Streamer, text string, encrypt bool) (string, error) {
+	t, err := c.Stream(encrypt)
+	if err != nil {
+		return "", err
+	}
+	head, err := t.Write(text)
+	if err != nil {
+		return "", err
+	}
+	tail, err := t.Flush()
+	if err != nil {
+		return "", err
+	}
+	return head + tail, nil
+}
+
+// ProcessStream читает текст из r частями, шифрует (encrypt = true)
+// или дешифрует его шифром c и пишет результат в w.
+func ProcessStream(c Streamer, r io.Reader, w io.Writer, encrypt bool) error {
+	t, err := c.Stream(encrypt)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, chunkSize)
+	var carry []byte // незавершённый UTF-8 символ в конце предыдущей части
+	for {
+		n, readErr := r.Read(buf)
+		data := append(carry, buf[:n]...)
+		cut := utf8Boundary(data)
+		if readErr != nil {
+			cut = len(data) // в конце входа неполный символ передаётся как есть
+		}
+		carry = append([]byte(nil), data[cut:]...)
+
+		if cut > 0 {
+			out, err := t.Write(string(data[:cut]))
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, out); err != nil {
+				return err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	out, err := t.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// utf8Boundary возвращает длину начала data, не разрывающего UTF-8 символ.
+func utf8Boundary(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
+
+// ProcessFile обрабатывает файл in (пусто или «-» — стандартный ввод)
+// и пишет результат в файл out (пусто или «-» — стандартный вывод).
+func ProcessFile(c Streamer, in, out string, encrypt bool) error {
+	r := io.Reader(os.Stdin)
+	if in != "" && in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return fmt.Errorf("не удалось открыть %q: %w", in, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	if out == "" || out == "-" {
+		return ProcessStream(c, r, os.Stdout, encrypt)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("не удалось создать %q: %w", out, err)
+	}
+	if err := ProcessStream(c, r, f, encrypt); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// StreamFlags — флаги неинтерактивной обработки: программа читает вход,
+// пишет результат и завершается, не показывая меню.
+type StreamFlags struct {
+	Encrypt bool
+	Decrypt bool
+	Key     string
+	In      string
+	Out     string
+}
+
+// RegisterStreamFlags регистрирует флаги -encrypt, -decrypt, -key, -in, -out.
+func RegisterStreamFlags(fs *flag.FlagSet) *StreamFlags {
+	f := &StreamFlags{}
+	fs.BoolVar(&f.Encrypt, "encrypt", false, "зашифровать вход без меню")
+	fs.BoolVar(&f.Decrypt, "decrypt", false, "расшифровать вход без меню")
+	fs.StringVar(&f.Key, "key", "", "ключ")
+	fs.StringVar(&f.In, "in", "-", "входной файл (- — стандартный ввод)")
+	fs.StringVar(&f.Out, "out", "-", "выходной файл (- — стандартный вывод)")
+	return f
+}
+
+// Active сообщает, запрошена ли неинтерактивная обработка.
+func (f *StreamFlags) Active() bool { return f.Encrypt || f.Decrypt }
+
+// Run обрабатывает вход шифром c согласно флагам.
+func (f *StreamFlags) Run(c Streamer) error {
+	if f.Encrypt == f.Decrypt {
+		return fmt.Errorf("укажите ровно один из флагов -encrypt и -decrypt")
+	}
+	return ProcessFile(c, f.In, f.Out, f.Encrypt)
+}
+
+// FileJob — параметры обработки файла, запрошенные в меню.
+type FileJob struct {
+	In, Out string
+	Encrypt bool
+}
+
+// ReadFileJob запрашивает направление, входной и выходной файлы.
+// Пустой выходной файл — вывод на экран.
+func ReadFileJob() (FileJob, error) {
+	var job FileJob
+	switch strings.TrimSpace(ReadLine("1 — зашифровать, 2 — расшифровать: ")) {
+	case "1":
+		job.Encrypt = true
+	case "2":
+	default:
+		return job, fmt.Errorf("неверный выбор")
+	}
+	job.In = strings.TrimSpace(ReadLine("Входной файл:  "))
+	if job.In == "" {
+		return job, fmt.Errorf("не указан входной файл")
+	}
+	job.Out = strings.TrimSpace(ReadLine("Выходной файл (пусто — вывести на экран): "))
+	return job, nil
+}
+
+// Run обрабатывает файл шифром c и сообщает о результате.
+func (job FileJob) Run(c Streamer) error {
+	if err := ProcessFile(c, job.In, job.Out, job.Encrypt); err != nil {
+		return err
+	}
+	if job.Out == "" {
+		fmt.Println()
+	} else {
+		fmt.Println("\nРезультат записан в", job.Out)
+	}
+	return nil
+}
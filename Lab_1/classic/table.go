@@ -0,0 +1,122 @@
+package classic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Table — ключевая таблица биграммных шифров (Плейфейр, двойной квадрат).
+type Table struct {
+	Grid [][]rune
+	Rows int
+	Cols int
+	Pos  map[rune][2]int // буква - {строка, столбец}
+}
+
+// BuildTable заполняет таблицу rows×cols буквами ключа (в порядке появления,
+// без повторов), а затем — оставшимися буквами алфавита.
+func BuildTable(key string, rows, cols int, alphabet string, norm func(rune) rune) *Table {
+	seen := make(map[rune]bool)
+	var order []rune
+
+	// Сначала добавляем буквы из ключа (в порядке появления, без повторов)
+	for _, r := range []rune(key) {
+		nr := norm(r)
+		if strings.ContainsRune(alphabet, nr) && !seen[nr] {
+			seen[nr] = true
+			order = append(order, nr)
+		}
+	}
+
+	// Затем — оставшиеся буквы алфавита
+	for _, r := range []rune(alphabet) {
+		if !seen[r] {
+			seen[r] = true
+			order = append(order, r)
+		}
+	}
+
+	grid := make([][]rune, rows)
+	pos := make(map[rune][2]int)
+	idx := 0
+	for i := range grid {
+		grid[i] = make([]rune, cols)
+		for j := range grid[i] {
+			if idx < len(order) {
+				grid[i][j] = order[idx]
+				pos[order[idx]] = [2]int{i, j}
+				idx++
+			}
+		}
+	}
+	return &Table{Grid: grid, Rows: rows, Cols: cols, Pos: pos}
+}
+
+// Print выводит таблицу на экран (для наглядности).
+func (t *Table) Print(title string) {
+	fmt.Printf("  %s:\n", title)
+	for _, row := range t.Grid {
+		fmt.Print("    ")
+		for _, r := range row {
+			fmt.Printf("%c ", r)
+		}
+		fmt.Println()
+	}
+}
+
+// Key записывает таблицу построчно — BuildTable превращает такой ключ в ту же таблицу.
+func (t *Table) Key() string {
+	var sb strings.Builder
+	for _, row := range t.Grid {
+		sb.WriteString(string(row))
+	}
+	return sb.String()
+}
+
+// Clone возвращает независимую копию таблицы.
+func (t *Table) Clone() *Table {
+	c := &Table{Grid: make([][]rune, t.Rows), Rows: t.Rows, Cols: t.Cols, Pos: make(map[rune][2]int, len(t.Pos))}
+	for i, row := range t.Grid {
+		c.Grid[i] = append([]rune(nil), row...)
+	}
+	for r, p := range t.Pos {
+		c.Pos[r] = p
+	}
+	return c
+}
+
+// Set записывает букву в ячейку, обновляя индекс позиций.
+func (t *Table) Set(i, j int, r rune) {
+	t.Grid[i][j] = r
+	t.Pos[r] = [2]int{i, j}
+}
+
+// SwapCells меняет местами две ячейки.
+func (t *Table) SwapCells(i1, j1, i2, j2 int) {
+	a, b := t.Grid[i1][j1], t.Grid[i2][j2]
+	t.Set(i1, j1, b)
+	t.Set(i2, j2, a)
+}
+
+// SwapRows меняет местами две строки.
+func (t *Table) SwapRows(i1, i2 int) {
+	for j := 0; j < t.Cols; j++ {
+		t.SwapCells(i1, j, i2, j)
+	}
+}
+
+// SwapCols меняет местами два столбца.
+func (t *Table) SwapCols(j1, j2 int) {
+	for i := 0; i < t.Rows; i++ {
+		t.SwapCells(i, j1, i, j2)
+	}
+}
+
+// Transpose отражает квадратную таблицу относительно главной диагонали.
+func (t *Table) Transpose() {
+	for i := 0; i < t.Rows; i++ {
+		for j := i + 1; j < t.Cols; j++ {
+			t.SwapCells(i, j, j, i)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package classic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token — символ исходного текста с признаками алфавита и регистра.
+// Биграммные шифры обрабатывают латиницу и кириллицу отдельными потоками,
+// а затем расставляют результат по позициям букв исходного текста.
+type Token struct {
+	R     rune
+	IsLat bool
+	IsCyr bool
+	Upper bool
+}
+
+// Tokenize разбирает текст на позиционированные токены.
+func Tokenize(text string) []Token {
+	runes := []rune(text)
+	tokens := make([]Token, len(runes))
+	for i, r := range runes {
+		tokens[i] = Token{
+			R:     r,
+			IsLat: IsLatinLetter(r),
+			IsCyr: IsCyrillicLetter(r),
+			Upper: unicode.IsUpper(r),
+		}
+	}
+	return tokens
+}
+
+// Streams собирает отдельные потоки латинских и русских букв (без нормализации).
+func Streams(tokens []Token) (lat, cyr []rune) {
+	for _, tok := range tokens {
+		if tok.IsLat {
+			lat = append(lat, tok.R)
+		} else if tok.IsCyr {
+			cyr = append(cyr, tok.R)
+		}
+	}
+	return lat, cyr
+}
+
+// Restore заменяет буквы токенов результатами обработки потоков (с сохранением
+// регистра оригинала); не-буквы остаются на местах. Если потоки стали длиннее
+// (заполнители при шифровании), лишние буквы дописываются в конец.
+func Restore(tokens []Token, latResult, cyrResult []rune) string {
+	r := restorer{tokens: tokens, lat: latResult, cyr: cyrResult}
+	var sb strings.Builder
+	r.emit(&sb, true)
+	return sb.String()
+}
+
+// restorer — потоковый вариант Restore: токены ждут, пока для них будут
+// готовы буквы результата, и выводятся строго по порядку.
+type restorer struct {
+	tokens   []Token
+	lat, cyr []rune
+}
+
+// emit выводит токены, для которых готовы буквы результата. При final
+// буквы без результата пропускаются, а лишние буквы результата дописываются в конец.
+func (r *restorer) emit(sb *strings.Builder, final bool) {
+	i := 0
+	for ; i < len(r.tokens); i++ {
+		tok := r.tokens[i]
+		var queue *[]rune
+		switch {
+		case tok.IsLat:
+			queue = &r.lat
+		case tok.IsCyr:
+			queue = &r.cyr
+		default:
+			sb.WriteRune(tok.R)
+			continue
+		}
+		if len(*queue) == 0 {
+			if final {
+				continue
+			}
+			break
+		}
+		out := (*queue)[0]
+		*queue = (*queue)[1:]
+		if tok.Upper {
+			out = unicode.ToUpper(out)
+		}
+		sb.WriteRune(out)
+	}
+	r.tokens = r.tokens[i:]
+
+	if final {
+		// Дописываем заполнители, если при шифровании текст стал длиннее
+		sb.WriteString(string(r.lat))
+		sb.WriteString(string(r.cyr))
+		r.lat, r.cyr = nil, nil
+	}
+}
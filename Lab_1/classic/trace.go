@@ -0,0 +1,229 @@
+package classic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Трассировка шифров сдвига (Виженер и его варианты, гаммирование): для каждой
+// буквы записывается, какой символ ключа к ней применён и как получен результат.
+
+// TraceStep — обработка одной буквы текста.
+type TraceStep struct {
+	Index     int    `json:"index"`      // номер буквы в тексте (с нуля, считаются только буквы)
+	Char      string `json:"char"`       // буква текста
+	Pos       int    `json:"pos"`        // её позиция в алфавите
+	Key       string `json:"key"`        // символ ключа (у генератора — 16-битное число гаммы)
+	KeyIndex  int    `json:"key_index"`  // позиция в ключе (в ключевом потоке — для самоключа и книги)
+	Shift     int    `json:"shift"`      // сдвиг, приведённый по модулю алфавита
+	N         int    `json:"n"`          // размер алфавита буквы
+	Formula   string `json:"formula"`    // вычисление, например «(7 + 4) mod 26 = 11»
+	ResultPos int    `json:"result_pos"` // позиция результата
+	Result    string `json:"result"`     // буква результата
+}
+
+// Tracer — шифр, умеющий сообщать о каждом шаге (см. SetTrace у Vigenere,
+// Gamma и GeneratorGamma).
+type Tracer interface {
+	Streamer
+	SetTrace(f func(TraceStep))
+}
+
+// TraceFormat — формат вывода трассировки.
+type TraceFormat int
+
+const (
+	TraceTable TraceFormat = iota // выровненная таблица
+	TraceCSV                      // CSV с заголовком
+	TraceJSON                     // JSON-массив шагов
+)
+
+func (f TraceFormat) String() string {
+	switch f {
+	case TraceCSV:
+		return "csv"
+	case TraceJSON:
+		return "json"
+	default:
+		return "table"
+	}
+}
+
+// TraceFormatFor выбирает формат по расширению файла (.csv, .json, иначе таблица).
+func TraceFormatFor(path string) TraceFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return TraceCSV
+	case ".json":
+		return TraceJSON
+	default:
+		return TraceTable
+	}
+}
+
+// Заголовки столбцов таблицы и CSV (в CSV — имена полей JSON).
+var (
+	traceTableHeader = []string{"№", "буква", "поз.", "ключ", "№ ключа", "сдвиг", "вычисление", "результат"}
+	traceCSVHeader   = []string{"index", "char", "pos", "key", "key_index", "shift", "n", "formula", "result_pos", "result"}
+)
+
+// TraceWriter пишет шаги трассировки по мере их поступления. Таблица
+// выравнивается при Close, CSV и JSON пишутся сразу.
+type TraceWriter struct {
+	format TraceFormat
+	w      io.Writer
+	tw     *tabwriter.Writer
+	cw     *csv.Writer
+	steps  int
+	err    error
+}
+
+// NewTraceWriter начинает вывод трассировки в w в формате format.
+func NewTraceWriter(w io.Writer, format TraceFormat) *TraceWriter {
+	t := &TraceWriter{format: format, w: w}
+	switch format {
+	case TraceCSV:
+		t.cw = csv.NewWriter(w)
+		t.err = t.cw.Write(traceCSVHeader)
+	case TraceJSON:
+		_, t.err = io.WriteString(w, "[")
+	default:
+		t.tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, t.err = fmt.Fprintln(t.tw, strings.Join(traceTableHeader, "\t"))
+	}
+	return t
+}
+
+// Step записывает очередной шаг; первая ошибка вывода возвращается из Close.
+func (t *TraceWriter) Step(s TraceStep) {
+	if t.err != nil {
+		return
+	}
+	switch t.format {
+	case TraceCSV:
+		t.err = t.cw.Write([]string{
+			strconv.Itoa(s.Index), s.Char, strconv.Itoa(s.Pos), s.Key, strconv.Itoa(s.KeyIndex),
+			strconv.Itoa(s.Shift), strconv.Itoa(s.N), s.Formula, strconv.Itoa(s.ResultPos), s.Result,
+		})
+	case TraceJSON:
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.err = err
+			return
+		}
+		sep := ",\n  "
+		if t.steps == 0 {
+			sep = "\n  "
+		}
+		_, t.err = fmt.Fprint(t.w, sep, string(data))
+	default:
+		_, t.err = fmt.Fprintf(t.tw, "%d\t%s\t%d\t%s\t%d\t%d\t%s\t%s\n",
+			s.Index, s.Char, s.Pos, s.Key, s.KeyIndex, s.Shift, s.Formula, s.Result)
+	}
+	t.steps++
+}
+
+// Close завершает вывод (закрывает JSON-массив, выравнивает таблицу).
+func (t *TraceWriter) Close() error {
+	if t.err != nil {
+		return t.err
+	}
+	switch t.format {
+	case TraceCSV:
+		t.cw.Flush()
+		return t.cw.Error()
+	case TraceJSON:
+		end := "\n]\n"
+		if t.steps == 0 {
+			end = "]\n"
+		}
+		_, err := io.WriteString(t.w, end)
+		return err
+	default:
+		return t.tw.Flush()
+	}
+}
+
+// Trace обрабатывает текст шифром c, передавая шаги в w, и возвращает результат.
+func Trace(c Tracer, text string, encrypt bool, w *TraceWriter) (string, error) {
+	c.SetTrace(w.Step)
+	defer c.SetTrace(nil)
+	out, err := runAll(c, text, encrypt)
+	if err != nil {
+		return "", err
+	}
+	return out, w.Close()
+}
+
+// TraceFile включает трассировку шифра c в файл path (формат — по расширению,
+// «-» — таблица на стандартный вывод) и возвращает функцию, завершающую вывод.
+func TraceFile(c Tracer, path string) (func() error, error) {
+	if path == "-" {
+		w := NewTraceWriter(os.Stdout, TraceTable)
+		c.SetTrace(w.Step)
+		return func() error {
+			c.SetTrace(nil)
+			return w.Close()
+		}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать %q: %w", path, err)
+	}
+	w := NewTraceWriter(f, TraceFormatFor(path))
+	c.SetTrace(w.Step)
+	return func() error {
+		c.SetTrace(nil)
+		if err := w.Close(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
+
+// RunTrace — диалог трассировки: запрашивает формат и файл (пусто — экран),
+// обрабатывает text шифром c и выводит шаги и результат.
+func RunTrace(c Tracer, text string, encrypt bool) error {
+	var format TraceFormat
+	switch strings.TrimSpace(ReadLine("Формат: 1 — таблица, 2 — CSV, 3 — JSON: ")) {
+	case "1":
+		format = TraceTable
+	case "2":
+		format = TraceCSV
+	case "3":
+		format = TraceJSON
+	default:
+		return fmt.Errorf("неверный выбор")
+	}
+	path := strings.TrimSpace(ReadLine("Файл трассировки (пусто — вывести на экран): "))
+
+	out := io.Writer(os.Stdout)
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("не удалось создать %q: %w", path, err)
+		}
+		defer f.Close()
+		out = f
+	} else {
+		fmt.Println()
+	}
+	result, err := Trace(c, text, encrypt, NewTraceWriter(out, format))
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		fmt.Println("Трассировка записана в", path)
+	}
+	fmt.Println("Результат:", result)
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,82 @@
+package classic
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTraceFormats сверяет вывод трассировки в каждом формате.
+func TestTraceFormats(t *testing.T) {
+	tests := []struct {
+		format TraceFormat
+		want   string
+	}{
+		{TraceTable, "" +
+			"№  буква  поз.  ключ  № ключа  сдвиг  вычисление          результат\n" +
+			"0  A      0     b     0        1      (0 + 1) mod 26 = 1  B\n" +
+			"1  b      1     b     0        1      (1 + 1) mod 26 = 2  c\n"},
+		{TraceCSV, "" +
+			"index,char,pos,key,key_index,shift,n,formula,result_pos,result\n" +
+			"0,A,0,b,0,1,26,(0 + 1) mod 26 = 1,1,B\n" +
+			"1,b,1,b,0,1,26,(1 + 1) mod 26 = 2,2,c\n"},
+		{TraceJSON, "[\n" +
+			`  {"index":0,"char":"A","pos":0,"key":"b","key_index":0,"shift":1,"n":26,"formula":"(0 + 1) mod 26 = 1","result_pos":1,"result":"B"},` + "\n" +
+			`  {"index":1,"char":"b","pos":1,"key":"b","key_index":0,"shift":1,"n":26,"formula":"(1 + 1) mod 26 = 2","result_pos":2,"result":"c"}` + "\n" +
+			"]\n"},
+	}
+	for _, tt := range tests {
+		v, err := NewVigenere("b", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		out, err := Trace(v, "Ab!", true, NewTraceWriter(&buf, tt.format))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if out != "Bc!" {
+			t.Errorf("%s: результат %q, ожидается \"Bc!\"", tt.format, out)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: трассировка\n%s\nожидается\n%s", tt.format, got, tt.want)
+		}
+		if v.trace != nil {
+			t.Errorf("%s: трассировка не выключена", tt.format)
+		}
+	}
+}
+
+// TestTraceFile проверяет, что функция завершения выключает трассировку
+// как для файла, так и для вывода на экран.
+func TestTraceFile(t *testing.T) {
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+
+	for _, path := range []string{filepath.Join(t.TempDir(), "trace.csv"), "-"} {
+		os.Stdout = devNull
+		v, err := NewVigenere("b", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done, err := TraceFile(v, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.trace == nil {
+			t.Errorf("%s: трассировка не включена", path)
+		}
+		if err := done(); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+		if v.trace != nil {
+			t.Errorf("%s: трассировка не выключена", path)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package classic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layout — расположение таблиц двойного квадрата.
+type Layout int
+
+const (
+	LayoutHorizontal Layout = iota // таблицы рядом: ключ 1 слева, ключ 2 справа
+	LayoutVertical                 // таблицы друг над другом: ключ 1 сверху, ключ 2 снизу
+)
+
+// Variant — вариант двойного квадрата: расположение таблиц и порядок букв на выходе.
+type Variant struct {
+	Layout     Layout
+	Transposed bool // буквы результата записываются в обратном порядке
+}
+
+func (v Variant) String() string {
+	s := "горизонтальный (таблицы рядом; буквы в одной строке меняются местами)"
+	if v.Layout == LayoutVertical {
+		s = "вертикальный (таблицы друг над другом; буквы в одном столбце не меняются)"
+	}
+	if v.Transposed {
+		s += ", с перестановкой букв результата"
+	}
+	return s
+}
+
+// TwoSquareBigram шифрует или дешифрует пару букв. Первая буква открытого текста ищется
+// в таблице t1 (ключ 1), вторая — в t2 (ключ 2); результат берётся из противоположных
+// углов прямоугольника, построенного на этих буквах с учётом расположения таблиц:
+//   - вертикальный: угол в строке первой буквы лежит в верхней таблице (t1), поэтому
+//     операция совпадает с обратной, а буквы в одном столбце остаются на месте;
+//   - горизонтальный: угол в строке первой буквы лежит в правой таблице (t2), поэтому
+//     буквы в одной строке меняются местами, а при дешифровании таблицы входа другие.
+func TwoSquareBigram(a, b rune, t1, t2 *Table, v Variant, encrypt bool) (rune, rune) {
+	if v.Transposed && !encrypt {
+		a, b = b, a
+	}
+
+	in1, in2 := t1, t2   // таблицы, в которых ищутся входные буквы
+	out1, out2 := t1, t2 // таблицы, из которых берутся буквы результата
+	if v.Layout == LayoutHorizontal {
+		if encrypt {
+			out1, out2 = t2, t1
+		} else {
+			in1, in2 = t2, t1
+		}
+	}
+
+	pa := in1.Pos[a]
+	pb := in2.Pos[b]
+	c1, c2 := out1.Grid[pa[0]][pb[1]], out2.Grid[pb[0]][pa[1]]
+
+	if v.Transposed && encrypt {
+		c1, c2 = c2, c1
+	}
+	return c1, c2
+}
+
+// TwoSquareLetters обрабатывает поток нормализованных букв одного алфавита.
+// Если количество букв нечётное — в конец добавляется заполнитель (filler), который уже должен быть нормализован.
+func TwoSquareLetters(letters []rune, filler rune, tL, tR *Table, v Variant, encrypt bool) []rune {
+	if len(letters)%2 != 0 {
+		letters = append(letters, filler)
+	}
+	result := make([]rune, 0, len(letters))
+	for i := 0; i < len(letters); i += 2 {
+		c1, c2 := TwoSquareBigram(letters[i], letters[i+1], tL, tR, v, encrypt)
+		result = append(result, c1, c2)
+	}
+	return result
+}
+
+// TwoSquare — шифр двойного квадрата: по паре таблиц на латиницу и кириллицу
+// (по умолчанию 5×5 и 4×8, см. Squares).
+type TwoSquare struct {
+	LatL, LatR *Table
+	CyrL, CyrR *Table
+	Squares    Squares
+	Variant    Variant
+}
+
+// NewTwoSquare строит пары таблиц (левая / правая) для каждого алфавита
+// (нулевое значение squares — таблицы по умолчанию).
+func NewTwoSquare(key TwoSquareKey, v Variant, squares Squares) (*TwoSquare, error) {
+	if key.Left == "" || key.Right == "" {
+		return nil, fmt.Errorf("оба ключа не могут быть пустыми")
+	}
+	squares = squares.orDefault()
+	if err := squares.Validate(); err != nil {
+		return nil, err
+	}
+	return &TwoSquare{
+		LatL:    squares[0].Build(key.Left),
+		LatR:    squares[0].Build(key.Right),
+		CyrL:    squares[1].Build(key.Left),
+		CyrR:    squares[1].Build(key.Right),
+		Squares: squares,
+		Variant: v,
+	}, nil
+}
+
+func (t *TwoSquare) Encrypt(text string) (string, error) { return runAll(t, text, true) }
+func (t *TwoSquare) Decrypt(text string) (string, error) { return runAll(t, text, false) }
+
+// Stream начинает потоковую обработку; незавершённая биграмма каждого
+// алфавита переносится между частями, нечётный остаток дополняется при Flush.
+func (t *TwoSquare) Stream(encrypt bool) (Transformer, error) {
+	sq := t.Squares.orDefault()
+	return &twoSquareTransformer{
+		states: [2]twoSquareState{
+			{bigram: t.bigram(t.LatL, t.LatR, encrypt), filler: sq[0].Filler, norm: sq[0].Norm},
+			{bigram: t.bigram(t.CyrL, t.CyrR, encrypt), filler: sq[1].Filler, norm: sq[1].Norm},
+		},
+		squares: sq,
+	}, nil
+}
+
+// bigram возвращает преобразование биграмм одного алфавита.
+func (t *TwoSquare) bigram(tL, tR *Table, encrypt bool) func(a, b rune) (rune, rune) {
+	return func(a, b rune) (rune, rune) { return TwoSquareBigram(a, b, tL, tR, t.Variant, encrypt) }
+}
+
+// twoSquareState — преобразование биграмм и незавершённая биграмма одного алфавита.
+type twoSquareState struct {
+	bigram  func(a, b rune) (rune, rune)
+	filler  rune
+	norm    func(rune) rune
+	pending rune
+	has     bool
+}
+
+// twoSquareTransformer — потоковая обработка биграммных шифров с раздельными
+// таблицами (двойной квадрат, четыре квадрата): буквы каждого алфавита
+// собираются в пары, регистр и не-буквы восстанавливаются по токенам.
+type twoSquareTransformer struct {
+	states  [2]twoSquareState
+	squares Squares
+	out     restorer
+}
+
+// pair обрабатывает биграмму алфавита i и добавляет её к результату.
+func (t *twoSquareTransformer) pair(i int, a, b rune) {
+	c1, c2 := t.states[i].bigram(a, b)
+	queue := &t.out.lat
+	if i == 1 {
+		queue = &t.out.cyr
+	}
+	*queue = append(*queue, c1, c2)
+}
+
+func (t *twoSquareTransformer) Write(text string) (string, error) {
+	tokens := t.squares.Tokenize(text)
+	t.out.tokens = append(t.out.tokens, tokens...)
+	for _, tok := range tokens {
+		i := 0
+		switch {
+		case tok.IsLat:
+		case tok.IsCyr:
+			i = 1
+		default:
+			continue
+		}
+		st := &t.states[i]
+		c := st.norm(tok.R)
+		if !st.has {
+			st.pending, st.has = c, true
+			continue
+		}
+		st.has = false
+		t.pair(i, st.pending, c)
+	}
+	var sb strings.Builder
+	t.out.emit(&sb, false)
+	return sb.String(), nil
+}
+
+func (t *twoSquareTransformer) Flush() (string, error) {
+	for i := range t.states {
+		// нечётное количество букв — в конец добавляется заполнитель
+		if st := &t.states[i]; st.has {
+			st.has = false
+			t.pair(i, st.pending, st.filler)
+		}
+	}
+	var sb strings.Builder
+	t.out.emit(&sb, true)
+	return sb.String(), nil
+}
@@ -0,0 +1,246 @@
+package classic
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ShiftRune сдвигает руну на сдвиг, заданный символом ключа (алфавиты по умолчанию).
+func ShiftRune(r rune, keyRune rune, encrypt bool) rune {
+	return DefaultAlphabets.ShiftRune(r, keyRune, encrypt)
+}
+
+// ShiftRune сдвигает руну на сдвиг, заданный символом ключа, по модулю её алфавита.
+func (s Alphabets) ShiftRune(r rune, keyRune rune, encrypt bool) rune {
+	return s.ShiftRuneOp(r, keyRune, OpVigenere, encrypt)
+}
+
+// ShiftOp — правило сдвига шифров семейства Виженера (P — открытый текст,
+// K — ключ, C — шифртекст; позиции букв складываются по модулю N).
+type ShiftOp int
+
+const (
+	OpVigenere        ShiftOp = iota // C = P + K
+	OpBeaufort                       // C = K − P; шифрование и расшифрование совпадают
+	OpVariantBeaufort                // C = P − K
+	OpGronsfeld                      // C = P + K, ключ — цифры (сдвиги 0–9)
+)
+
+func (op ShiftOp) String() string {
+	switch op {
+	case OpBeaufort:
+		return "Бофор (C = K − P)"
+	case OpVariantBeaufort:
+		return "вариант Бофора (C = P − K)"
+	case OpGronsfeld:
+		return "Гронсфельд (цифровой ключ)"
+	default:
+		return "Виженер (C = P + K)"
+	}
+}
+
+// keyShift возвращает сдвиг, заданный символом ключа: для Гронсфельда цифра —
+// это сам сдвиг, иначе — позиция буквы в её алфавите (или -1).
+func (s Alphabets) keyShift(keyRune rune, op ShiftOp) int {
+	if op == OpGronsfeld && keyRune >= '0' && keyRune <= '9' {
+		return int(keyRune - '0')
+	}
+	_, keyPos := s.Lookup(keyRune)
+	return keyPos
+}
+
+// ShiftRuneOp сдвигает руну по правилу op на сдвиг, заданный символом ключа,
+// по модулю её алфавита.
+func (s Alphabets) ShiftRuneOp(r rune, keyRune rune, op ShiftOp, encrypt bool) rune {
+	return s.shiftLetter(r, s.keyShift(keyRune, op), op, encrypt, nil)
+}
+
+// shiftLetter сдвигает букву r на shift позиций по правилу op по модулю её
+// алфавита (shift < 0 — символ ключа вне алфавитов, r не меняется).
+// Если step не nil, в него записываются подробности вычисления.
+func (s Alphabets) shiftLetter(r rune, shift int, op ShiftOp, encrypt bool, step *TraceStep) rune {
+	a, pos := s.Lookup(r)
+	if a == nil || shift < 0 {
+		return r
+	}
+	alpha := a.Letters
+	alphaLen := len(alpha)
+
+	// сложение/вычитание позиций по модулю N
+	// (ключ из другого алфавита может быть длиннее N, поэтому приводим его по модулю)
+	shift %= alphaLen
+	newPos := shiftPos(pos, shift, alphaLen, op, encrypt)
+
+	result := alpha[newPos]
+	if unicode.IsUpper(r) {
+		result = unicode.ToUpper(result)
+	}
+	if step != nil {
+		step.Char, step.Pos, step.Shift, step.N = string(r), pos, shift, alphaLen
+		step.Formula = shiftFormula(pos, shift, alphaLen, op, encrypt)
+		step.ResultPos, step.Result = newPos, string(result)
+	}
+	return result
+}
+
+// shiftPos применяет правило op к позиции pos со сдвигом shift по модулю n.
+func shiftPos(pos, shift, n int, op ShiftOp, encrypt bool) int {
+	switch {
+	case op == OpBeaufort: // P = K − C, та же операция
+		return (shift - pos + n) % n
+	case (op == OpVariantBeaufort) == encrypt: // вычитание ключа
+		return (pos - shift + n) % n
+	default:
+		return (pos + shift) % n
+	}
+}
+
+// shiftFormula записывает вычисление shiftPos, например «(7 + 4) mod 26 = 11».
+func shiftFormula(pos, shift, n int, op ShiftOp, encrypt bool) string {
+	a, sign, b := pos, "+", shift
+	switch {
+	case op == OpBeaufort:
+		a, sign, b = shift, "-", pos
+	case (op == OpVariantBeaufort) == encrypt:
+		sign = "-"
+	}
+	return fmt.Sprintf("(%d %s %d) mod %d = %d", a, sign, b, n, shiftPos(pos, shift, n, op, encrypt))
+}
+
+// FilterKey оставляет в ключе только буквы алфавитов по умолчанию.
+func FilterKey(key string) []rune {
+	return DefaultAlphabets.FilterKey(key)
+}
+
+// KeyMode — способ получения ключевого потока шифра Виженера.
+type KeyMode int
+
+const (
+	KeyRepeat  KeyMode = iota // ключ повторяется циклически
+	KeyAuto                   // самоключ: после ключа идут буквы открытого текста
+	KeyRunning                // бегущий ключ: буквы текста-книги, не короче сообщения
+)
+
+func (m KeyMode) String() string {
+	switch m {
+	case KeyAuto:
+		return "самоключ"
+	case KeyRunning:
+		return "бегущий ключ (текст книги)"
+	default:
+		return "повторяющийся ключ"
+	}
+}
+
+// Vigenere — шифр Виженера. Ключ сдвигается только по буквам текста,
+// не-буквы переносятся без изменений.
+type Vigenere struct {
+	key       []rune
+	op        ShiftOp
+	mode      KeyMode
+	alphabets Alphabets
+	trace     func(TraceStep)
+}
+
+// NewVigenere проверяет ключ и создаёт шифр с повторяющимся ключом над набором
+// алфавитов (nil — латиница и русский алфавит).
+func NewVigenere(key VigenereKey, alphabets Alphabets) (*Vigenere, error) {
+	return NewVigenereMode(key, KeyRepeat, alphabets)
+}
+
+// NewVigenereMode создаёт шифр с заданным способом получения ключевого потока.
+// Для KeyAuto key — начальный ключ, для KeyRunning — текст книги.
+func NewVigenereMode(key VigenereKey, mode KeyMode, alphabets Alphabets) (*Vigenere, error) {
+	return NewShiftCipher(key, OpVigenere, mode, alphabets)
+}
+
+// NewShiftCipher создаёт шифр семейства Виженера с правилом сдвига op.
+// Для Гронсфельда в ключе остаются только цифры, для остальных — буквы алфавитов.
+func NewShiftCipher(key VigenereKey, op ShiftOp, mode KeyMode, alphabets Alphabets) (*Vigenere, error) {
+	if key == "" {
+		return nil, fmt.Errorf("ключ не может быть пустым")
+	}
+	alphabets = alphabets.orDefault()
+	var filtered []rune
+	if op == OpGronsfeld {
+		for _, r := range key {
+			if r >= '0' && r <= '9' {
+				filtered = append(filtered, r)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, fmt.Errorf("ключ Гронсфельда должен содержать хотя бы одну цифру")
+		}
+	} else if filtered = alphabets.FilterKey(string(key)); len(filtered) == 0 {
+		return nil, fmt.Errorf("ключ должен содержать хотя бы одну букву")
+	}
+	return &Vigenere{key: filtered, op: op, mode: mode, alphabets: alphabets}, nil
+}
+
+func (v *Vigenere) Encrypt(text string) (string, error) { return runAll(v, text, true) }
+func (v *Vigenere) Decrypt(text string) (string, error) { return runAll(v, text, false) }
+
+// SetTrace включает трассировку: f вызывается для каждой буквы (nil — выключить).
+func (v *Vigenere) SetTrace(f func(TraceStep)) { v.trace = f }
+
+// Stream начинает потоковую обработку; позиция ключа переносится между частями.
+func (v *Vigenere) Stream(encrypt bool) (Transformer, error) {
+	return &vigenereStream{v: v, encrypt: encrypt}, nil
+}
+
+// vigenereStream — позиция в ключевом потоке и (для самоключа) буквы
+// открытого текста, которые ещё понадобятся как ключ.
+type vigenereStream struct {
+	v        *Vigenere
+	encrypt  bool
+	keyIndex int
+	plain    []rune // очередь букв открытого текста — продолжение самоключа
+}
+
+func (st *vigenereStream) Write(text string) (string, error) {
+	v := st.v
+	var sb strings.Builder
+	for _, r := range text {
+		if v.alphabets.AlphabetFor(r) == nil {
+			sb.WriteRune(r)
+			continue
+		}
+
+		var keyRune rune
+		keyIndex := st.keyIndex
+		switch {
+		case v.mode == KeyRepeat:
+			keyIndex %= len(v.key)
+			keyRune = v.key[keyIndex]
+		case st.keyIndex < len(v.key):
+			keyRune = v.key[st.keyIndex]
+		case v.mode == KeyAuto:
+			keyRune, st.plain = st.plain[0], st.plain[1:]
+		default:
+			return "", fmt.Errorf("текст книги короче сообщения: %d букв", len(v.key))
+		}
+
+		var step *TraceStep
+		if v.trace != nil {
+			step = &TraceStep{Index: st.keyIndex, Key: string(keyRune), KeyIndex: keyIndex}
+		}
+		out := v.alphabets.shiftLetter(r, v.alphabets.keyShift(keyRune, v.op), v.op, st.encrypt, step)
+		if step != nil {
+			v.trace(*step)
+		}
+		sb.WriteRune(out)
+		st.keyIndex++
+
+		if v.mode == KeyAuto {
+			if st.encrypt {
+				st.plain = append(st.plain, r)
+			} else {
+				st.plain = append(st.plain, out)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+func (st *vigenereStream) Flush() (string, error) { return "", nil }
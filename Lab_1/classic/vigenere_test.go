@@ -0,0 +1,137 @@
+package classic
+
+import "testing"
+
+// TestAutokeyVector — пример самоключа из литературы: начальный ключ QUEENLY,
+// открытый текст ATTACKATDAWN.
+func TestAutokeyVector(t *testing.T) {
+	v, err := NewVigenereMode("QUEENLY", KeyAuto, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := v.Encrypt("ATTACKATDAWN"); got != "QNXEPVYTWTWP" {
+		t.Errorf("шифртекст %q, ожидается QNXEPVYTWTWP", got)
+	}
+	if got, _ := v.Decrypt("QNXEPVYTWTWP"); got != "ATTACKATDAWN" {
+		t.Errorf("расшифровано %q, ожидается ATTACKATDAWN", got)
+	}
+}
+
+// TestKeyModesRoundTrip проверяет расшифрование в режимах самоключа и бегущего
+// ключа на тексте с обоими алфавитами, регистром и не-буквами.
+func TestKeyModesRoundTrip(t *testing.T) {
+	const text = "Attack at dawn! Атака на рассвете, 5 утра."
+	for _, tc := range []struct {
+		key  VigenereKey
+		mode KeyMode
+	}{
+		{"queenly ключ", KeyAuto},
+		{"It was the best of times, it was the worst of times. Все счастливые семьи похожи", KeyRunning},
+	} {
+		v, err := NewVigenereMode(tc.key, tc.mode, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := v.Encrypt(text)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.mode, err)
+		}
+		dec, err := v.Decrypt(enc)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.mode, err)
+		}
+		if dec != text {
+			t.Errorf("%s: расшифровано %q, ожидается %q", tc.mode, dec, text)
+		}
+	}
+}
+
+// TestRunningKey проверяет, что бегущий ключ — это повторяющийся ключ длиной
+// не меньше сообщения, а короткая книга отвергается.
+func TestRunningKey(t *testing.T) {
+	const book = "thequickbrownfoxjumpsoverthelazydog"
+	running, err := NewVigenereMode(book, KeyRunning, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeat, err := NewVigenere(book, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const text = "Meet me near the old bridge"
+	got, err := running.Encrypt(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := repeat.Encrypt(text); got != want {
+		t.Errorf("бегущий ключ: %q, ожидается %q", got, want)
+	}
+
+	short, err := NewVigenereMode("book", KeyRunning, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := short.Encrypt(text); err == nil {
+		t.Error("книга короче сообщения принята")
+	}
+}
+
+// TestBeaufortVector — пример шифра Бофора из литературы: ключ FORTIFICATION.
+// Шифр взаимно обратен, поэтому повторное шифрование возвращает текст.
+func TestBeaufortVector(t *testing.T) {
+	const plain, cipher = "DEFENDTHEEASTWALLOFTHECASTLE", "CKMPVCPVWPIWUJOGIUAPVWRIWUUK"
+	b, err := NewShiftCipher("FORTIFICATION", OpBeaufort, KeyRepeat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := b.Encrypt(plain); got != cipher {
+		t.Errorf("шифртекст %q, ожидается %q", got, cipher)
+	}
+	if got, _ := b.Encrypt(cipher); got != plain {
+		t.Errorf("повторное шифрование %q, ожидается %q", got, plain)
+	}
+	if got, _ := b.Decrypt(cipher); got != plain {
+		t.Errorf("расшифровано %q, ожидается %q", got, plain)
+	}
+}
+
+// TestShiftOps проверяет связь правил сдвига с шифром Виженера: вариант
+// Бофора шифрует, как Виженер расшифровывает, а Гронсфельд с ключом 31415 —
+// это Виженер с ключом DBEBF (сдвиги 3, 1, 4, 1, 5).
+func TestShiftOps(t *testing.T) {
+	const text = "Attack at dawn! Атака на рассвете."
+	vig, err := NewVigenere("dbebf", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	variant, err := NewShiftCipher("dbebf", OpVariantBeaufort, KeyRepeat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gronsfeld, err := NewShiftCipher("31415", OpGronsfeld, KeyRepeat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := vig.Decrypt(text)
+	if got, _ := variant.Encrypt(text); got != want {
+		t.Errorf("вариант Бофора: %q, ожидается %q", got, want)
+	}
+	want, _ = vig.Encrypt(text)
+	if got, _ := gronsfeld.Encrypt(text); got != want {
+		t.Errorf("Гронсфельд: %q, ожидается %q", got, want)
+	}
+	if got, _ := gronsfeld.Encrypt("ATTACKATDAWN"); got != "DUXBHNBXEFZO" {
+		t.Errorf("Гронсфельд: %q, ожидается DUXBHNBXEFZO", got)
+	}
+
+	for _, c := range []*Vigenere{variant, gronsfeld} {
+		enc, _ := c.Encrypt(text)
+		if dec, _ := c.Decrypt(enc); dec != text {
+			t.Errorf("%s: расшифровано %q, ожидается %q", c.op, dec, text)
+		}
+	}
+	if _, err := NewShiftCipher("key", OpGronsfeld, KeyRepeat, nil); err == nil {
+		t.Error("ключ Гронсфельда без цифр принят")
+	}
+}
@@ -23,8 +23,7 @@ func xorBlocks(a, b [8]byte) [8]byte {
 // encryptCBC шифрует открытый текст в режиме CBC.
 // Возвращает IV (8 байт) + шифртекст, объединённые в одном срезе.
 // Схема: C[i] = E_K(P[i] XOR C[i-1]),  C[0] = IV
-func encryptCBC(plaintext []byte, key [8]byte, iv [8]byte) []byte {
-	subkeys := descore.GenerateSubkeys(key)
+func encryptCBC(plaintext []byte, c *descore.Cipher, iv [8]byte) []byte {
 	padded := descore.PadPKCS7(plaintext)
 
 	out := make([]byte, 8+len(padded))
@@ -38,8 +37,8 @@ func encryptCBC(plaintext []byte, key [8]byte, iv [8]byte) []byte {
 		// XOR открытого блока с предыдущим блоком шифртекста (или IV)
 		xored := xorBlocks(block, prev)
 
-		// Шифрование одного блока DES
-		encrypted := descore.DesBlock(xored, subkeys)
+		// Шифрование одного блока (DES или тройной DES)
+		encrypted := c.EncryptBlock(xored)
 		copy(out[8+i:], encrypted[:])
 
 		prev = encrypted
@@ -50,7 +49,7 @@ func encryptCBC(plaintext []byte, key [8]byte, iv [8]byte) []byte {
 // decryptCBC дешифрует шифртекст в режиме CBC.
 // Принимает IV (8 байт) + шифртекст, объединённые в одном срезе.
 // Схема: P[i] = D_K(C[i]) XOR C[i-1],  C[0] = IV
-func decryptCBC(data []byte, key [8]byte) ([]byte, error) {
+func decryptCBC(data []byte, c *descore.Cipher) ([]byte, error) {
 	if len(data) < 16 || (len(data)-8)%8 != 0 {
 		return nil, fmt.Errorf("неверная длина данных (ожидается IV + шифртекст, кратный 8 байтам)")
 	}
@@ -60,16 +59,14 @@ func decryptCBC(data []byte, key [8]byte) ([]byte, error) {
 	copy(iv[:], data[:8])
 	ciphertext := data[8:]
 
-	revSubkeys := descore.ReverseSubkeys(descore.GenerateSubkeys(key))
-
 	plaintext := make([]byte, len(ciphertext))
 	prev := iv
 	for i := 0; i < len(ciphertext); i += 8 {
 		var block [8]byte
 		copy(block[:], ciphertext[i:i+8])
 
-		// Дешифрование одного блока DES
-		decrypted := descore.DesBlock(block, revSubkeys)
+		// Дешифрование одного блока (DES или тройной DES)
+		decrypted := c.DecryptBlock(block)
 
 		// XOR расшифрованного блока с предыдущим блоком шифртекста (или IV)
 		xored := xorBlocks(decrypted, prev)
@@ -82,7 +79,8 @@ func decryptCBC(data []byte, key [8]byte) ([]byte, error) {
 
 func main() {
 	fmt.Println()
-	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт)")
+	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт) — DES")
+	fmt.Println("         32/48 hex-символов (16/24 байта) — тройной DES (EDE2/EDE3)")
 	fmt.Println("  IV   : 16 hex-символов (8 байт); оставьте пустым для случайного IV")
 	fmt.Println("  Формат вывода: hex(IV) || hex(шифртекст)")
 	fmt.Println()
@@ -105,14 +103,20 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
 			iv, err := cliutil.ParseIV(ivStr)
 			if err != nil {
 				fmt.Println("Ошибка IV:", err)
 				continue
 			}
 
-			result := encryptCBC([]byte(text), key, iv)
-			fmt.Println("\nIV (hex):                  ", hex.EncodeToString(iv[:]))
+			result := encryptCBC([]byte(text), c, iv)
+			fmt.Println("\nАлгоритм:                  ", c)
+			fmt.Println("IV (hex):                  ", hex.EncodeToString(iv[:]))
 			fmt.Println("Зашифрованный текст (hex): ", hex.EncodeToString(result[8:]))
 			fmt.Println("IV || шифртекст (hex):     ", hex.EncodeToString(result))
 			fmt.Println()
@@ -130,8 +134,13 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
 
-			plaintext, err := decryptCBC(data, key)
+			plaintext, err := decryptCBC(data, c)
 			if err != nil {
 				fmt.Println("Ошибка дешифрования:", err)
 				continue
@@ -1,135 +1,133 @@
 package main
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"cliutil"
 	"descore"
 )
 
-//  DES-CFB: шифрование и дешифрование
-
-// xorBytes применяет побайтовый XOR среза a со срезом b длиной n байт.
-func xorBytes(a, b []byte, n int) []byte {
-	out := make([]byte, n)
-	for i := 0; i < n; i++ {
-		out[i] = a[i] ^ b[i]
-	}
-	return out
-}
-
-// encryptCFB шифрует открытый текст в режиме CFB-64 (полноблочный, 64-битный сдвиг).
-// Схема для каждого 8-байтного блока:
+//  DES-CFB-k: шифрование и дешифрование с сегментом k бит (NIST SP 800-38A, п. 6.3)
 //
-//	O[i] = E_K( I[i] )          — шифрование сдвигового регистра
-//	C[i] = P[i] XOR O[i]        — XOR с открытым текстом
-//	I[i+1] = C[i]               — сдвиговый регистр <- блок шифртекста
+//	I[1]   = IV
+//	O[j]   = E_K( I[j] )                  — шифрование сдвигового регистра
+//	C[j]   = P[j] XOR MSB_k( O[j] )       — сегмент открытого текста (k бит)
+//	I[j+1] = LSB_{64-k}( I[j] ) || C[j]   — регистр сдвигается на k бит,
+//	                                        справа входит сегмент шифртекста
 //
-// I[0] = IV.
-// Дополнение не требуется: последний неполный блок обрабатывается частичным XOR.
-// Возвращает IV (8 байт) || шифртекст.
-func encryptCFB(plaintext []byte, key [8]byte, iv [8]byte) []byte {
-	subkeys := descore.GenerateSubkeys(key)
-
-	// Результат: IV || шифртекст (без дополнения)
-	out := make([]byte, 8+len(plaintext))
-	copy(out[:8], iv[:])
-
-	shiftReg := iv // сдвиговый регистр, начальное значение = IV
+// Сообщение рассматривается как строка бит; последний сегмент может быть
+// короче k — для него берутся старшие биты O[j], а регистр уже не нужен.
+// Дополнение не требуется. CFB-64 — полноблочный режим, CFB-8 — побайтный,
+// CFB-1 — побитный (один вызов DES на каждый бит).
+
+// getBits возвращает n бит (n ≤ 64) строки data начиная с бита pos
+// (бит 0 — старший бит первого байта), выровненные вправо.
+func getBits(data []byte, pos, n int) uint64 {
+	var v uint64
+	for i := pos; i < pos+n; i++ {
+		v = v<<1 | uint64(data[i/8]>>(7-i%8)&1)
+	}
+	return v
+}
 
-	for i := 0; i < len(plaintext); i += 8 {
-		// Шифруем сдвиговый регистр
-		keystream := descore.DesBlock(shiftReg, subkeys)
+// putBits записывает младшие n бит v в строку data начиная с бита pos.
+func putBits(data []byte, pos, n int, v uint64) {
+	for i := pos + n - 1; i >= pos; i-- {
+		if v&1 == 1 {
+			data[i/8] |= 1 << (7 - i%8)
+		} else {
+			data[i/8] &^= 1 << (7 - i%8)
+		}
+		v >>= 1
+	}
+}
 
-		// Определяем длину текущего блока (последний может быть короче 8 байт)
-		end := i + 8
-		if end > len(plaintext) {
-			end = len(plaintext)
+// cfb обрабатывает строку бит in в режиме CFB-k. Разница между шифрованием
+// и дешифрованием — только в том, что попадает в регистр: сегмент шифртекста
+// (выход при шифровании, вход при дешифровании).
+func cfb(in []byte, c *descore.Cipher, iv [8]byte, segment int, encrypt bool) []byte {
+	out := make([]byte, len(in))
+	reg := binary.BigEndian.Uint64(iv[:]) // сдвиговый регистр I[j]
+	total := len(in) * 8
+
+	for pos := 0; pos < total; pos += segment {
+		n := min(segment, total-pos) // последний сегмент может быть короче
+
+		// O[j] = E_K(I[j]); берём старшие n бит
+		var block [8]byte
+		binary.BigEndian.PutUint64(block[:], reg)
+		o := c.EncryptBlock(block)
+		keystream := binary.BigEndian.Uint64(o[:]) >> (64 - n)
+
+		x := getBits(in, pos, n)
+		y := x ^ keystream
+		putBits(out, pos, n, y)
+
+		// I[j+1] = LSB_{64-k}(I[j]) || C[j]
+		ct := y
+		if !encrypt {
+			ct = x
 		}
-		blockLen := end - i
-
-		// C[i] = P[i] XOR O[i] (только нужное количество байт)
-		cBlock := xorBytes(plaintext[i:end], keystream[:], blockLen)
-		copy(out[8+i:], cBlock)
-
-		// Следующий сдвиговый регистр = блок шифртекста
-		// Для неполного последнего блока используем частичный шифртекст,
-		// остаток дополняем нулями (реально следующего блока нет)
-		copy(shiftReg[:blockLen], cBlock)
-		// хвост обнуляем только при необходимости (неполный блок)
-		for j := blockLen; j < 8; j++ {
-			shiftReg[j] = 0
+		if segment == 64 {
+			reg = ct
+		} else {
+			reg = reg<<segment | ct
 		}
 	}
 	return out
 }
 
-// decryptCFB дешифрует шифртекст в режиме CFB-64.
+// encryptCFB шифрует открытый текст в режиме CFB-k (k = segment бит, 1–64).
+// Возвращает IV (8 байт) || шифртекст.
+func encryptCFB(plaintext []byte, c *descore.Cipher, iv [8]byte, segment int) []byte {
+	out := make([]byte, 8, 8+len(plaintext))
+	copy(out, iv[:])
+	return append(out, cfb(plaintext, c, iv, segment, true)...)
+}
+
+// decryptCFB дешифрует шифртекст в режиме CFB-k.
 // Принимает IV (8 байт) || шифртекст, объединённые в одном срезе.
-// Схема:
-//
-//	O[i] = E_K( I[i] )          — шифрование сдвигового регистра (то же, что при шифровании!)
-//	P[i] = C[i] XOR O[i]        — восстановление открытого текста
-//	I[i+1] = C[i]               — сдвиговый регистр <- блок шифртекста
-func decryptCFB(data []byte, key [8]byte) ([]byte, error) {
+// Для дешифрования используется то же E_K (шифрование блока).
+func decryptCFB(data []byte, c *descore.Cipher, segment int) ([]byte, error) {
 	if len(data) < 8 {
 		return nil, fmt.Errorf("данные слишком короткие: ожидается минимум IV (8 байт)")
 	}
-
-	// Извлекаем IV и шифртекст
 	var iv [8]byte
 	copy(iv[:], data[:8])
-	ciphertext := data[8:]
-
-	if len(ciphertext) == 0 {
-		return []byte{}, nil
-	}
-
-	// В режиме CFB для дешифрования используется то же E_K (шифрование DES),
-	// подключи в прямом порядке.
-	subkeys := descore.GenerateSubkeys(key)
-
-	plaintext := make([]byte, len(ciphertext))
-	shiftReg := iv // сдвиговый регистр
-
-	for i := 0; i < len(ciphertext); i += 8 {
-		// Шифруем сдвиговый регистр
-		keystream := descore.DesBlock(shiftReg, subkeys)
-
-		end := i + 8
-		if end > len(ciphertext) {
-			end = len(ciphertext)
-		}
-		blockLen := end - i
-
-		// Следующий сдвиговый регистр = блок ШИФРТЕКСТА (до XOR)
-		var nextReg [8]byte
-		copy(nextReg[:blockLen], ciphertext[i:end])
-
-		// P[i] = C[i] XOR O[i]
-		pBlock := xorBytes(ciphertext[i:end], keystream[:], blockLen)
-		copy(plaintext[i:], pBlock)
+	return cfb(data[8:], c, iv, segment, false), nil
+}
 
-		shiftReg = nextReg
+// parseSegment разбирает размер сегмента CFB в битах (1–64).
+func parseSegment(input string) (int, error) {
+	k, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || k < 1 || k > 64 {
+		return 0, fmt.Errorf("размер сегмента — целое число бит от 1 до 64")
 	}
-	return plaintext, nil
+	return k, nil
 }
 
 func main() {
+	segment := 64
+
 	fmt.Println()
-	fmt.Println("Шифр DES — режим ОСШ (Обратная связь по шифру, CFB-64)")
-	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт)")
+	fmt.Println("Шифр DES — режим ОСШ (Обратная связь по шифру, CFB-k)")
+	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт) — DES")
+	fmt.Println("         32/48 hex-символов (16/24 байта) — тройной DES (EDE2/EDE3)")
 	fmt.Println("  IV   : 16 hex-символов (8 байт); оставьте пустым для случайного IV")
 	fmt.Println("  Дополнение: не требуется (поточный режим)")
 	fmt.Println("  Формат вывода: hex(IV) || hex(шифртекст)")
 	fmt.Println()
 
 	for {
+		fmt.Printf("Режим: CFB-%d (сегмент %d бит)\n", segment, segment)
 		fmt.Println("Выберите действие:")
 		fmt.Println("  1 — Зашифровать")
 		fmt.Println("  2 — Расшифровать")
+		fmt.Println("  3 — Размер сегмента (CFB-1, CFB-8, CFB-64 или любой от 1 до 64 бит)")
 		fmt.Println("  0 — Выход")
 		choice := strings.TrimSpace(cliutil.ReadLine(": "))
 
@@ -144,14 +142,20 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
 			iv, err := cliutil.ParseIV(ivStr)
 			if err != nil {
 				fmt.Println("Ошибка IV:", err)
 				continue
 			}
 
-			result := encryptCFB([]byte(text), key, iv)
-			fmt.Println("\nIV (hex):                  ", hex.EncodeToString(iv[:]))
+			result := encryptCFB([]byte(text), c, iv, segment)
+			fmt.Printf("\nАлгоритм:                   %s, CFB-%d\n", c, segment)
+			fmt.Println("IV (hex):                  ", hex.EncodeToString(iv[:]))
 			fmt.Println("Зашифрованный текст (hex): ", hex.EncodeToString(result[8:]))
 			fmt.Println("IV || шифртекст (hex):     ", hex.EncodeToString(result))
 			fmt.Println()
@@ -169,8 +173,13 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
 
-			plaintext, err := decryptCFB(data, key)
+			plaintext, err := decryptCFB(data, c, segment)
 			if err != nil {
 				fmt.Println("Ошибка дешифрования:", err)
 				continue
@@ -178,6 +187,18 @@ func main() {
 			fmt.Println("\nРасшифрованный текст:", string(plaintext))
 			fmt.Println()
 
+		case "3":
+			fmt.Println("  1 — CFB-1 (побитный)")
+			fmt.Println("  8 — CFB-8 (побайтный)")
+			fmt.Println("  64 — CFB-64 (полноблочный)")
+			k, err := parseSegment(cliutil.ReadLine("Размер сегмента в битах (1–64): "))
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			segment = k
+			fmt.Println()
+
 		case "0":
 			fmt.Println("Выход.")
 			return
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/hex"
+	"math/rand/v2"
+	"testing"
+
+	"descore"
+)
+
+// Приложение D FIPS 81 (DES Modes of Operation): ключ 0123456789abcdef,
+// IV 1234567890abcdef, открытый текст «Now is the time for all ».
+// В SP 800-38A контрольные примеры есть только для AES, поэтому для DES
+// берутся примеры FIPS 81, а для тройного DES и прочих k — сравнение
+// с независимой реализацией поверх crypto/des.
+var fips81 = []struct {
+	segment int
+	ct      string
+}{
+	{8, "f31fda07011462ee187f43d80a7cd9b5b0d290da6e5b9a87"},
+	{64, "f3096249c7f46e51a69e839b1a92f78403467133898ea622"},
+}
+
+func mustCipher(t *testing.T, key []byte) *descore.Cipher {
+	t.Helper()
+	c, err := descore.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCFBVectors(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789abcdef")
+	iv := [8]byte{0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef}
+	pt := []byte("Now is the time for all ")
+	c := mustCipher(t, key)
+
+	for _, v := range fips81 {
+		out := encryptCFB(pt, c, iv, v.segment)
+		if got := hex.EncodeToString(out[8:]); got != v.ct {
+			t.Errorf("CFB-%d: шифртекст %s, ожидается %s", v.segment, got, v.ct)
+		}
+		back, err := decryptCFB(out, c, v.segment)
+		if err != nil || !bytes.Equal(back, pt) {
+			t.Errorf("CFB-%d: дешифрование %q (%v), ожидается %q", v.segment, back, err, pt)
+		}
+	}
+}
+
+// referenceCFB — CFB-k по определению SP 800-38A над crypto/des: регистр и
+// сообщение хранятся как массивы бит (по байту на бит).
+func referenceCFB(block cipher.Block, iv, in []byte, k int, encrypt bool) []byte {
+	toBits := func(b []byte) []byte {
+		bits := make([]byte, 8*len(b))
+		for i := range bits {
+			bits[i] = b[i/8] >> (7 - i%8) & 1
+		}
+		return bits
+	}
+	fromBits := func(bits []byte) []byte {
+		b := make([]byte, len(bits)/8)
+		for i, bit := range bits {
+			b[i/8] |= bit << (7 - i%8)
+		}
+		return b
+	}
+
+	reg := toBits(iv)
+	src := toBits(in)
+	dst := make([]byte, len(src))
+	o := make([]byte, 8)
+	for pos := 0; pos < len(src); pos += k {
+		n := min(k, len(src)-pos)
+		block.Encrypt(o, fromBits(reg))
+		ob := toBits(o)
+		for i := range n {
+			dst[pos+i] = src[pos+i] ^ ob[i]
+		}
+		ct := dst[pos : pos+n]
+		if !encrypt {
+			ct = src[pos : pos+n]
+		}
+		reg = append(reg[n:], ct...)
+	}
+	return fromBits(dst)
+}
+
+func tripleKey(key []byte) []byte {
+	if len(key) == 16 {
+		return append(append([]byte{}, key...), key[:8]...)
+	}
+	return key
+}
+
+func TestCFBMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(rng.Uint32())
+		}
+		return b
+	}
+
+	for _, keyLen := range []int{8, 16, 24} {
+		key := random(keyLen)
+		c := mustCipher(t, key)
+		var ref cipher.Block
+		if keyLen == 8 {
+			ref, _ = des.NewCipher(key)
+		} else {
+			ref, _ = des.NewTripleDESCipher(tripleKey(key))
+		}
+
+		for _, k := range []int{1, 5, 8, 12, 16, 32, 63, 64} {
+			var iv [8]byte
+			copy(iv[:], random(8))
+			pt := random(1 + rng.IntN(40))
+
+			out := encryptCFB(pt, c, iv, k)
+			want := referenceCFB(ref, iv[:], pt, k, true)
+			if !bytes.Equal(out[8:], want) {
+				t.Fatalf("%s, CFB-%d: шифртекст %x, ожидается %x", c, k, out[8:], want)
+			}
+			back, err := decryptCFB(out, c, k)
+			if err != nil || !bytes.Equal(back, pt) {
+				t.Fatalf("%s, CFB-%d: дешифрование %x (%v), ожидается %x", c, k, back, err, pt)
+			}
+		}
+	}
+}
+
+// TestCFB64MatchesCryptoDES сравнивает полноблочный режим с cipher.NewCFBEncrypter,
+// включая неполный последний блок.
+func TestCFB64MatchesCryptoDES(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 8))
+	for _, keyLen := range []int{8, 24} {
+		for range 50 {
+			key := make([]byte, keyLen)
+			iv := make([]byte, 8)
+			pt := make([]byte, rng.IntN(50))
+			for _, b := range [][]byte{key, iv, pt} {
+				for i := range b {
+					b[i] = byte(rng.Uint32())
+				}
+			}
+			var ref cipher.Block
+			if keyLen == 8 {
+				ref, _ = des.NewCipher(key)
+			} else {
+				ref, _ = des.NewTripleDESCipher(key)
+			}
+			want := make([]byte, len(pt))
+			cipher.NewCFBEncrypter(ref, iv).XORKeyStream(want, pt)
+
+			out := encryptCFB(pt, mustCipher(t, key), [8]byte(iv), 64)
+			if !bytes.Equal(out[8:], want) {
+				t.Fatalf("ключ %x: шифртекст %x, ожидается %x", key, out[8:], want)
+			}
+		}
+	}
+}
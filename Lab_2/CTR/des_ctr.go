@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cliutil"
+	"descore"
+)
+
+//  DES-CTR: шифрование и дешифрование режимом счётчика
+//
+//  Блок счётчика (8 байт) = nonce || счётчик; счётчик занимает младшие
+//  width байт, nonce — остальные. Начальный блок T[0] = IV.
+//
+//	T[i] = nonce || (счётчик IV + i)   — счётчик не переходит в nonce
+//	O[i] = E_K( T[i] )                 — гамма
+//	C[i] = P[i] XOR O[i]
+//
+//  Гамма байта с номером n зависит только от n, поэтому любой участок
+//  шифртекста можно расшифровать, не обрабатывая данные перед ним.
+
+// defaultCounterWidth — ширина счётчика по умолчанию (32 бита).
+const defaultCounterWidth = 4
+
+// minNonceBytes — наименьший nonce, при котором пустой IV даёт случайный nonce
+// и счётчик с нуля: при 4 случайных байтах совпадение nonce двух сообщений
+// ожидается лишь примерно через 2^16 сообщений под одним ключом.
+const minNonceBytes = 4
+
+// blankIV готовит случайный IV random для пустого ввода. Если nonce достаточно
+// длинный, счётчик начинается с нуля — так до переполнения больше всего
+// блоков. Иначе nonce почти или совсем не остаётся, и нулевой счётчик
+// повторял бы гамму между сообщениями, поэтому случайным остаётся и начало
+// счётчика; старший бит счётчика обнуляется, чтобы до переполнения оставалось
+// не меньше половины его диапазона.
+func blankIV(random [8]byte, width int) [8]byte {
+	iv := random
+	if 8-width >= minNonceBytes {
+		clear(iv[8-width:])
+	} else {
+		iv[8-width] &= 0x7f
+	}
+	return iv
+}
+
+// counterBlock возвращает блок счётчика T[index] для IV и ширины счётчика width байт.
+// Переполнение счётчика — ошибка: иначе гамма повторилась бы.
+func counterBlock(iv [8]byte, width int, index uint64) ([8]byte, error) {
+	var ctr uint64
+	for _, b := range iv[8-width:] {
+		ctr = ctr<<8 | uint64(b)
+	}
+	limit := ^uint64(0)
+	if width < 8 {
+		limit = 1<<(8*width) - 1
+	}
+	if index > limit-ctr {
+		return iv, fmt.Errorf("счётчик шириной %d байт переполнится на блоке %d: уменьшите сообщение или увеличьте ширину счётчика", width, index)
+	}
+	ctr += index
+
+	block := iv
+	for i := 7; i >= 8-width; i-- {
+		block[i] = byte(ctr)
+		ctr >>= 8
+	}
+	return block, nil
+}
+
+// xorCTR накладывает гамму на data, начиная с байта offset потока.
+// Обрабатываются только блоки, покрывающие data.
+func xorCTR(data []byte, offset uint64, c *descore.Cipher, iv [8]byte, width int) ([]byte, error) {
+	out := make([]byte, len(data))
+	index, skip := offset/8, int(offset%8)
+	for i := 0; i < len(data); index++ {
+		block, err := counterBlock(iv, width, index)
+		if err != nil {
+			return nil, err
+		}
+		keystream := c.EncryptBlock(block)
+
+		// первый блок участка может начинаться с середины
+		n := copy(out[i:], keystream[skip:])
+		for j := i; j < i+n; j++ {
+			out[j] ^= data[j]
+		}
+		i += n
+		skip = 0
+	}
+	return out, nil
+}
+
+// encryptCTR шифрует открытый текст в режиме CTR.
+// Возвращает IV (8 байт) || шифртекст.
+func encryptCTR(plaintext []byte, c *descore.Cipher, iv [8]byte, width int) ([]byte, error) {
+	ciphertext, err := xorCTR(plaintext, 0, c, iv, width)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 8+len(ciphertext))
+	copy(out[:8], iv[:])
+	copy(out[8:], ciphertext)
+	return out, nil
+}
+
+// decryptCTR дешифрует шифртекст в режиме CTR.
+// Принимает: IV (8 байт) || шифртекст.
+func decryptCTR(data []byte, c *descore.Cipher, width int) ([]byte, error) {
+	return decryptRange(data, c, width, 0, len(data)-8)
+}
+
+// decryptRange дешифрует байты открытого текста [from, from+n) из данных
+// IV || шифртекст; гамма вычисляется только для блоков этого участка.
+func decryptRange(data []byte, c *descore.Cipher, width int, from, n int) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("данные слишком короткие: ожидается минимум 8 байт (IV)")
+	}
+	var iv [8]byte
+	copy(iv[:], data[:8])
+	ciphertext := data[8:]
+
+	if from < 0 || n < 0 || from > len(ciphertext) || n > len(ciphertext)-from {
+		return nil, fmt.Errorf("диапазон %d–%d вне шифртекста (%d байт)", from, from+n, len(ciphertext))
+	}
+	return xorCTR(ciphertext[from:from+n], uint64(from), c, iv, width)
+}
+
+// readKey запрашивает ключ и строит шифр (DES или тройной DES по длине ключа).
+func readKey(prompt string) (*descore.Cipher, error) {
+	key, err := cliutil.ParseKey(cliutil.ReadLine(prompt))
+	if err != nil {
+		return nil, err
+	}
+	return descore.NewCipher(key)
+}
+
+// readCiphertext запрашивает hex (IV || шифртекст).
+func readCiphertext() ([]byte, error) {
+	hexData := strings.TrimSpace(cliutil.ReadLine("Введите hex (IV || шифртекст): "))
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return nil, fmt.Errorf("неверный hex-формат: %w", err)
+	}
+	return data, nil
+}
+
+func main() {
+	width := defaultCounterWidth
+
+	fmt.Println()
+	fmt.Println("Шифр DES — режим счётчика (Counter, CTR)")
+	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт) — DES")
+	fmt.Println("         32/48 hex-символов (16/24 байта) — тройной DES (EDE2/EDE3)")
+	fmt.Println("  IV   : 16 hex-символов (8 байт) = nonce || начальный счётчик;")
+	fmt.Println("         оставьте пустым для случайного nonce и счётчика с нуля")
+	fmt.Println("         (при nonce короче 4 байт случаен и начальный счётчик)")
+	fmt.Println("  Дополнение: не требуется (потоковый режим)")
+	fmt.Println("  Формат вывода: hex(IV) || hex(шифртекст)")
+	fmt.Println()
+
+	for {
+		fmt.Printf("Счётчик: младшие %d байт блока, nonce: старшие %d байт\n", width, 8-width)
+		fmt.Println("Выберите действие:")
+		fmt.Println("  1 — Зашифровать")
+		fmt.Println("  2 — Расшифровать")
+		fmt.Println("  3 — Расшифровать диапазон байтов (произвольный доступ)")
+		fmt.Println("  4 — Ширина счётчика")
+		fmt.Println("  0 — Выход")
+		choice := strings.TrimSpace(cliutil.ReadLine(": "))
+
+		switch choice {
+		case "1":
+			text := cliutil.ReadLine("Введите текст:  ")
+			c, err := readKey("Введите ключ:   ")
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
+			ivStr := cliutil.ReadLine("Введите IV (hex, пусто = случайный nonce): ")
+			iv, err := cliutil.ParseIV(ivStr)
+			if err != nil {
+				fmt.Println("Ошибка IV:", err)
+				continue
+			}
+			if strings.TrimSpace(ivStr) == "" {
+				iv = blankIV(iv, width)
+			}
+
+			result, err := encryptCTR([]byte(text), c, iv, width)
+			if err != nil {
+				fmt.Println("Ошибка шифрования:", err)
+				continue
+			}
+			fmt.Println("\nАлгоритм:                  ", c)
+			fmt.Println("IV (hex):                  ", hex.EncodeToString(iv[:]))
+			fmt.Println("Зашифрованный текст (hex): ", hex.EncodeToString(result[8:]))
+			fmt.Println("IV || шифртекст (hex):     ", hex.EncodeToString(result))
+			fmt.Printf("Ширина счётчика (%d байт) в шифртексте не записывается: расшифровывайте с той же шириной.\n", width)
+			fmt.Println()
+
+		case "2":
+			data, err := readCiphertext()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			c, err := readKey("Введите ключ:                  ")
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
+
+			plaintext, err := decryptCTR(data, c, width)
+			if err != nil {
+				fmt.Println("Ошибка дешифрования:", err)
+				continue
+			}
+			fmt.Println("\nРасшифрованный текст:", string(plaintext))
+			fmt.Println()
+
+		case "3":
+			data, err := readCiphertext()
+			if err != nil {
+				fmt.Println("Ошибка:", err)
+				continue
+			}
+			c, err := readKey("Введите ключ:                  ")
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
+			from, err1 := strconv.Atoi(strings.TrimSpace(cliutil.ReadLine("Первый байт (с нуля):          ")))
+			n, err2 := strconv.Atoi(strings.TrimSpace(cliutil.ReadLine("Число байт:                    ")))
+			if err1 != nil || err2 != nil {
+				fmt.Println("Ошибка: начало и длина диапазона — целые числа")
+				continue
+			}
+
+			plaintext, err := decryptRange(data, c, width, from, n)
+			if err != nil {
+				fmt.Println("Ошибка дешифрования:", err)
+				continue
+			}
+			fmt.Printf("\nБайты %d–%d (hex): %s\n", from, from+n, hex.EncodeToString(plaintext))
+			fmt.Println("Как текст:       ", string(plaintext))
+			fmt.Println()
+
+		case "4":
+			w, err := strconv.Atoi(strings.TrimSpace(cliutil.ReadLine("Ширина счётчика в байтах (1–8): ")))
+			if err != nil || w < 1 || w > 8 {
+				fmt.Println("Неверная ширина, оставлено", width)
+				continue
+			}
+			width = w
+			fmt.Println()
+
+		case "0":
+			fmt.Println("Выход.")
+			return
+
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
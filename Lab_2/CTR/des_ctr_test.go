@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/hex"
+	"testing"
+
+	"descore"
+)
+
+var (
+	testKey = mustHex("0123456789abcdef")
+	testIV  = [8]byte{0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef}
+	// 43 байта: последний блок неполный
+	testText = []byte("Now is the time for all good men to come...")
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func mustCipher(t *testing.T, key []byte) *descore.Cipher {
+	t.Helper()
+	c, err := descore.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCTRRoundTrip(t *testing.T) {
+	c := mustCipher(t, testKey)
+	for width := 1; width <= 8; width++ {
+		iv := testIV
+		clear(iv[8-width:]) // счётчик с нуля, чтобы ширины 1 хватило на сообщение
+		out, err := encryptCTR(testText, c, iv, width)
+		if err != nil {
+			t.Fatalf("ширина %d: %v", width, err)
+		}
+		if !bytes.Equal(out[:8], iv[:]) {
+			t.Errorf("ширина %d: вывод начинается с %x, ожидается IV %x", width, out[:8], iv)
+		}
+		back, err := decryptCTR(out, c, width)
+		if err != nil || !bytes.Equal(back, testText) {
+			t.Errorf("ширина %d: дешифрование %q (%v), ожидается %q", width, back, err, testText)
+		}
+	}
+}
+
+// TestCTRMatchesStdlib сравнивает CTR со счётчиком во весь блок
+// с cipher.NewCTR над crypto/des, в том числе при переносе через байты IV.
+func TestCTRMatchesStdlib(t *testing.T) {
+	c := mustCipher(t, testKey)
+	block, err := des.NewCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iv := range [][8]byte{testIV, {0, 0, 0, 0, 0xff, 0xff, 0xff, 0xfe}} {
+		out, err := encryptCTR(testText, c, iv, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := make([]byte, len(testText))
+		cipher.NewCTR(block, iv[:]).XORKeyStream(want, testText)
+		if !bytes.Equal(out[8:], want) {
+			t.Errorf("IV %x: шифртекст %x, ожидается %x", iv, out[8:], want)
+		}
+	}
+}
+
+// TestDecryptRange проверяет, что участок, начинающийся и кончающийся
+// в середине блоков, совпадает с частью полного дешифрования.
+func TestDecryptRange(t *testing.T) {
+	c := mustCipher(t, testKey)
+	const width = 4
+	data, err := encryptCTR(testText, c, testIV, width)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := decryptCTR(data, c, width)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range [][2]int{{0, 0}, {0, 43}, {3, 2}, {5, 11}, {7, 17}, {8, 8}, {13, 30}, {42, 1}, {43, 0}} {
+		from, n := r[0], r[1]
+		got, err := decryptRange(data, c, width, from, n)
+		if err != nil {
+			t.Errorf("байты %d–%d: %v", from, from+n, err)
+			continue
+		}
+		if !bytes.Equal(got, full[from:from+n]) {
+			t.Errorf("байты %d–%d: %q, ожидается %q", from, from+n, got, full[from:from+n])
+		}
+	}
+	for _, r := range [][2]int{{-1, 2}, {40, 4}, {44, 0}, {0, -1}} {
+		if _, err := decryptRange(data, c, width, r[0], r[1]); err == nil {
+			t.Errorf("диапазон %d+%d принят", r[0], r[1])
+		}
+	}
+}
+
+// TestCounterOverflow проверяет, что однобайтовый счётчик не переходит
+// в nonce, а переполнение — ошибка, а не повтор гаммы.
+func TestCounterOverflow(t *testing.T) {
+	iv := [8]byte{1, 2, 3, 4, 5, 6, 7, 0xfe}
+	last, err := counterBlock(iv, 1, 1)
+	if err != nil || last != [8]byte{1, 2, 3, 4, 5, 6, 7, 0xff} {
+		t.Errorf("блок 1: %x (%v)", last, err)
+	}
+	if _, err := counterBlock(iv, 1, 2); err == nil {
+		t.Error("переполнение счётчика шириной 1 байт не обнаружено")
+	}
+	c := mustCipher(t, testKey)
+	if _, err := encryptCTR(testText, c, iv, 1); err == nil {
+		t.Error("сообщение длиннее диапазона счётчика зашифровано")
+	}
+}
+
+// TestBlankIV проверяет пустой IV: при длинном nonce счётчик начинается
+// с нуля, при коротком начало счётчика остаётся случайным, но не выше
+// середины диапазона.
+func TestBlankIV(t *testing.T) {
+	random := [8]byte{0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x97, 0x88}
+	for width := 1; width <= 8; width++ {
+		iv := blankIV(random, width)
+		if 8-width >= minNonceBytes {
+			want := random
+			clear(want[8-width:])
+			if iv != want {
+				t.Errorf("ширина %d: IV %x, ожидается %x", width, iv, want)
+			}
+			continue
+		}
+		if iv == [8]byte{} || iv[8-width]&0x80 != 0 {
+			t.Errorf("ширина %d: IV %x — счётчик нулевой или в верхней половине", width, iv)
+		}
+		if !bytes.Equal(iv[9-width:], random[9-width:]) {
+			t.Errorf("ширина %d: IV %x, младшие байты счётчика не случайны", width, iv)
+		}
+	}
+}
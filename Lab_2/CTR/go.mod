@@ -0,0 +1,13 @@
+module ctr
+
+go 1.25.0
+
+require (
+	cliutil v0.0.0
+	descore v0.0.0
+)
+
+replace (
+	cliutil => ../cliutil
+	descore => ../descore
+)
@@ -12,30 +12,28 @@ import (
 //  DES-ECB: шифрование и дешифрование произвольного сообщения
 
 // encryptECB шифрует текст в режиме ECB.
-func encryptECB(plaintext []byte, key [8]byte) []byte {
-	subkeys := descore.GenerateSubkeys(key)
+func encryptECB(plaintext []byte, c *descore.Cipher) []byte {
 	padded := descore.PadPKCS7(plaintext)
 	ciphertext := make([]byte, len(padded))
 	for i := 0; i < len(padded); i += 8 {
 		var block [8]byte
 		copy(block[:], padded[i:i+8])
-		result := descore.DesBlock(block, subkeys)
+		result := c.EncryptBlock(block)
 		copy(ciphertext[i:], result[:])
 	}
 	return ciphertext
 }
 
 // decryptECB дешифрует текст в режиме ECB.
-func decryptECB(ciphertext []byte, key [8]byte) ([]byte, error) {
+func decryptECB(ciphertext []byte, c *descore.Cipher) ([]byte, error) {
 	if len(ciphertext)%8 != 0 {
 		return nil, fmt.Errorf("длина шифртекста должна быть кратна 8 байтам")
 	}
-	revSubkeys := descore.ReverseSubkeys(descore.GenerateSubkeys(key))
 	plaintext := make([]byte, len(ciphertext))
 	for i := 0; i < len(ciphertext); i += 8 {
 		var block [8]byte
 		copy(block[:], ciphertext[i:i+8])
-		result := descore.DesBlock(block, revSubkeys)
+		result := c.DecryptBlock(block)
 		copy(plaintext[i:], result[:])
 	}
 	return descore.UnpadPKCS7(plaintext)
@@ -44,7 +42,8 @@ func decryptECB(ciphertext []byte, key [8]byte) ([]byte, error) {
 func main() {
 	fmt.Println()
 	fmt.Println("Шифр DES — режим ЭКК (Электронная кодовая книга, ECB)")
-	fmt.Println("  Ключ  : до 8 символов  ИЛИ  16 hex-символов (8 байт)")
+	fmt.Println("  Ключ  : до 8 символов  ИЛИ  16 hex-символов (8 байт) — DES")
+	fmt.Println("          32/48 hex-символов (16/24 байта) — тройной DES (EDE2/EDE3)")
 	fmt.Println("  Дополнение: PKCS#7")
 	fmt.Println()
 
@@ -64,8 +63,14 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
-			ciphertext := encryptECB([]byte(text), key)
-			fmt.Println("\nЗашифрованный текст (hex):", hex.EncodeToString(ciphertext))
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
+			ciphertext := encryptECB([]byte(text), c)
+			fmt.Println("\nАлгоритм:", c)
+			fmt.Println("Зашифрованный текст (hex):", hex.EncodeToString(ciphertext))
 			fmt.Println()
 
 		case "2":
@@ -81,7 +86,12 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
-			plaintext, err := decryptECB(ciphertext, key)
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
+			plaintext, err := decryptECB(ciphertext, c)
 			if err != nil {
 				fmt.Println("Ошибка дешифрования:", err)
 				continue
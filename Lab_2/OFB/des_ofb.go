@@ -19,14 +19,13 @@ func xorBytes(a, b []byte) []byte {
 }
 
 // generateKeystream вырабатывает гамму (keystream) длиной n байт в режиме OFB.
-func generateKeystream(n int, key [8]byte, iv [8]byte) []byte {
-	subkeys := descore.GenerateSubkeys(key)
+func generateKeystream(n int, c *descore.Cipher, iv [8]byte) []byte {
 	keystream := make([]byte, 0, n)
 
 	register := iv
 	for len(keystream) < n {
 		// Шифруем регистр обратной связи
-		register = descore.DesBlock(register, subkeys)
+		register = c.EncryptBlock(register)
 		keystream = append(keystream, register[:]...)
 	}
 	return keystream[:n]
@@ -34,8 +33,8 @@ func generateKeystream(n int, key [8]byte, iv [8]byte) []byte {
 
 // encryptOFB шифрует открытый текст в режиме OFB.
 // Возвращает IV (8 байт)  шифртекст.
-func encryptOFB(plaintext []byte, key [8]byte, iv [8]byte) []byte {
-	keystream := generateKeystream(len(plaintext), key, iv)
+func encryptOFB(plaintext []byte, c *descore.Cipher, iv [8]byte) []byte {
+	keystream := generateKeystream(len(plaintext), c, iv)
 	ciphertext := xorBytes(plaintext, keystream)
 
 	out := make([]byte, 8+len(ciphertext))
@@ -46,7 +45,7 @@ func encryptOFB(plaintext []byte, key [8]byte, iv [8]byte) []byte {
 
 // decryptOFB дешифрует шифртекст в режиме OFB.
 // Принимает: IV (8 байт) || шифртекст.
-func decryptOFB(data []byte, key [8]byte) ([]byte, error) {
+func decryptOFB(data []byte, c *descore.Cipher) ([]byte, error) {
 	if len(data) < 8 {
 		return nil, fmt.Errorf("данные слишком короткие: ожидается минимум 8 байт (IV)")
 	}
@@ -59,14 +58,15 @@ func decryptOFB(data []byte, key [8]byte) ([]byte, error) {
 		return []byte{}, nil
 	}
 
-	keystream := generateKeystream(len(ciphertext), key, iv)
+	keystream := generateKeystream(len(ciphertext), c, iv)
 	return xorBytes(ciphertext, keystream), nil
 }
 
 func main() {
 	fmt.Println()
 	fmt.Println("Шифр DES — режим ОСВ (Обратная связь по выходу, OFB)")
-	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт)")
+	fmt.Println("  Ключ : до 8 символов  ИЛИ  16 hex-символов (8 байт) — DES")
+	fmt.Println("         32/48 hex-символов (16/24 байта) — тройной DES (EDE2/EDE3)")
 	fmt.Println("  IV   : 16 hex-символов (8 байт); оставьте пустым для случайного IV")
 	fmt.Println("  Дополнение: не требуется (потоковый режим)")
 	fmt.Println("  Формат вывода: hex(IV) || hex(шифртекст)")
@@ -90,14 +90,20 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
 			iv, err := cliutil.ParseIV(ivStr)
 			if err != nil {
 				fmt.Println("Ошибка IV:", err)
 				continue
 			}
 
-			result := encryptOFB([]byte(text), key, iv)
-			fmt.Println("\nIV (hex):                  ", hex.EncodeToString(iv[:]))
+			result := encryptOFB([]byte(text), c, iv)
+			fmt.Println("\nАлгоритм:                  ", c)
+			fmt.Println("IV (hex):                  ", hex.EncodeToString(iv[:]))
 			fmt.Println("Зашифрованный текст (hex): ", hex.EncodeToString(result[8:]))
 			fmt.Println("IV || шифртекст (hex):     ", hex.EncodeToString(result))
 			fmt.Println()
@@ -115,8 +121,13 @@ func main() {
 				fmt.Println("Ошибка ключа:", err)
 				continue
 			}
+			c, err := descore.NewCipher(key)
+			if err != nil {
+				fmt.Println("Ошибка ключа:", err)
+				continue
+			}
 
-			plaintext, err := decryptOFB(data, key)
+			plaintext, err := decryptOFB(data, c)
 			if err != nil {
 				fmt.Println("Ошибка дешифрования:", err)
 				continue
@@ -20,27 +20,28 @@ func ReadLine(prompt string) string {
 	return stdinScanner.Text()
 }
 
-// ParseKey разбирает строку ключа:
-//   - 16 hex-символов → 8 байт
-//   - до 8 символов текста (дополняется нулями до 8 байт)
-func ParseKey(input string) ([8]byte, error) {
+// ParseKey разбирает строку ключа DES или тройного DES:
+//   - 16, 32 или 48 hex-символов → 8, 16 или 24 байта (DES, 3DES-EDE2, 3DES-EDE3)
+//   - до 8 символов текста → 8 байт (DES), дополняется нулями
+//
+// Тройной DES выбирается только длиной hex-ключа: более длинный текст —
+// ошибка, а не молча выбранный другой алгоритм.
+func ParseKey(input string) ([]byte, error) {
 	input = strings.TrimSpace(input)
-	var key [8]byte
 
-	if len(input) == 16 {
-		b, err := hex.DecodeString(input)
-		if err == nil {
-			copy(key[:], b)
-			return key, nil
+	switch len(input) {
+	case 16, 32, 48:
+		if b, err := hex.DecodeString(input); err == nil {
+			return b, nil
 		}
 	}
 
 	keyBytes := []byte(input)
 	if len(keyBytes) > 8 {
-		keyBytes = keyBytes[:8]
+		return nil, fmt.Errorf("текстовый ключ длиннее 8 байт (%d): для DES введите до 8 символов, "+
+			"для тройного DES — 32 или 48 hex-символов", len(keyBytes))
 	}
-	copy(key[:], keyBytes)
-	return key, nil
+	return append(keyBytes, make([]byte, 8-len(keyBytes))...), nil
 }
 
 // ParseIV разбирает строку IV: ровно 16 hex-символов (8 байт).
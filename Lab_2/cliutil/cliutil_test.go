@@ -0,0 +1,45 @@
+package cliutil
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []byte
+	}{
+		{"secret", []byte("secret\x00\x00")},
+		{"12345678", []byte("12345678")},
+		{"ключ", []byte("ключ")}, // 8 байт UTF-8
+		{"  0123456789abcdef ", []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+		{"0123456789abcdef" + "fedcba9876543210", []byte{
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10}},
+		{"000102030405060708090a0b0c0d0e0f1011121314151617", []byte{
+			0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23}},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.input)
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("ParseKey(%q) = %x, %v; ожидается %x", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+// TestParseKeyRejectsLongText проверяет, что текст длиннее 8 байт не становится
+// ключом тройного DES и не обрезается до DES.
+func TestParseKeyRejectsLongText(t *testing.T) {
+	for _, input := range []string{
+		"password1",
+		"sixteen-chars-ok",         // 16 символов, но не hex
+		"twenty-four-characters!!", // 24 символа
+		"0123456789abcdef0",        // 17 hex-символов
+		"ключ!",                    // 9 байт UTF-8
+	} {
+		if got, err := ParseKey(input); err == nil {
+			t.Errorf("ParseKey(%q) = %x, ожидается ошибка", input, got)
+		}
+	}
+}
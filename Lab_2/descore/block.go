@@ -0,0 +1,44 @@
+package descore
+
+import "crypto/cipher"
+
+//  Адаптер crypto/cipher.Block
+//
+// *Cipher реализует cipher.Block, поэтому DES и тройной DES этого пакета
+// можно использовать с режимами и обёртками стандартной библиотеки:
+//
+//	c, _ := descore.NewCipher(key)
+//	cipher.NewCBCEncrypter(c, iv).CryptBlocks(dst, src)
+//	cipher.NewCTR(c, iv).XORKeyStream(dst, src)
+
+// BlockSize — размер блока DES в байтах.
+const BlockSize = 8
+
+var _ cipher.Block = (*Cipher)(nil)
+
+// BlockSize возвращает размер блока (8 байт).
+func (c *Cipher) BlockSize() int { return BlockSize }
+
+// Encrypt шифрует первый блок src в dst. Как и в crypto/des, короткие
+// src или dst — ошибка программы (panic); dst и src могут совпадать.
+func (c *Cipher) Encrypt(dst, src []byte) {
+	checkBlock(dst, src)
+	out := c.EncryptBlock([8]byte(src))
+	copy(dst, out[:])
+}
+
+// Decrypt дешифрует первый блок src в dst (требования те же, что у Encrypt).
+func (c *Cipher) Decrypt(dst, src []byte) {
+	checkBlock(dst, src)
+	out := c.DecryptBlock([8]byte(src))
+	copy(dst, out[:])
+}
+
+func checkBlock(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("descore: входные данные короче блока")
+	}
+	if len(dst) < BlockSize {
+		panic("descore: выходной буфер короче блока")
+	}
+}
@@ -0,0 +1,103 @@
+package descore
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"math/rand/v2"
+	"testing"
+)
+
+// referenceCipher возвращает шифр crypto/des для того же ключа
+// (EDE2 в crypto/des задаётся 24-байтным ключом K1 K2 K1).
+func referenceCipher(t *testing.T, key []byte) cipher.Block {
+	t.Helper()
+	var (
+		c   cipher.Block
+		err error
+	)
+	switch len(key) {
+	case 8:
+		c, err = des.NewCipher(key)
+	case 16:
+		c, err = des.NewTripleDESCipher(append(append([]byte{}, key...), key[:8]...))
+	default:
+		c, err = des.NewTripleDESCipher(key)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func randomBytes(rng *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rng.Uint32())
+	}
+	return b
+}
+
+// TestCipherMatchesCryptoDES сравнивает шифрование и дешифрование блоков
+// со стандартной библиотекой на случайных ключах и блоках.
+func TestCipherMatchesCryptoDES(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, keyLen := range []int{8, 16, 24} {
+		for range 500 {
+			key := randomBytes(rng, keyLen)
+			src := randomBytes(rng, BlockSize)
+
+			c, err := NewCipher(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref := referenceCipher(t, key)
+
+			got, want := make([]byte, BlockSize), make([]byte, BlockSize)
+			c.Encrypt(got, src)
+			ref.Encrypt(want, src)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%s, ключ %x, блок %x: шифрование %x, ожидается %x", c, key, src, got, want)
+			}
+			c.Decrypt(got, want)
+			if !bytes.Equal(got, src) {
+				t.Fatalf("%s, ключ %x: дешифрование %x, ожидается %x", c, key, got, src)
+			}
+		}
+	}
+}
+
+// TestCipherWithStandardModes проверяет работу адаптера в режимах
+// crypto/cipher: результат должен совпадать с теми же режимами над crypto/des.
+func TestCipherWithStandardModes(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	for _, keyLen := range []int{8, 16, 24} {
+		key := randomBytes(rng, keyLen)
+		iv := randomBytes(rng, BlockSize)
+		src := randomBytes(rng, 16*BlockSize)
+
+		c, err := NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref := referenceCipher(t, key)
+
+		got, want := make([]byte, len(src)), make([]byte, len(src))
+		cipher.NewCBCEncrypter(c, iv).CryptBlocks(got, src)
+		cipher.NewCBCEncrypter(ref, iv).CryptBlocks(want, src)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: CBC расходится с crypto/des", c)
+		}
+		cipher.NewCBCDecrypter(c, iv).CryptBlocks(got, got)
+		if !bytes.Equal(got, src) {
+			t.Errorf("%s: CBC не восстанавливает открытый текст", c)
+		}
+
+		n := len(src) - 3 // CTR не требует кратности блоку
+		cipher.NewCTR(c, iv).XORKeyStream(got[:n], src[:n])
+		cipher.NewCTR(ref, iv).XORKeyStream(want[:n], src[:n])
+		if !bytes.Equal(got[:n], want[:n]) {
+			t.Errorf("%s: CTR расходится с crypto/des", c)
+		}
+	}
+}
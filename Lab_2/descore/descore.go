@@ -2,7 +2,11 @@
 // Используется режимами как общая библиотека для шифрования и дешифрования блоков, а также генерации подключей.
 package descore
 
-import "fmt"
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
 
 //  Таблицы DES (стандарт FIPS 46-3)
 
@@ -57,7 +61,7 @@ var PC2 = [48]byte{
 // KeyShifts — количество сдвигов для каждого из 16 раундов
 var KeyShifts = [16]byte{1, 1, 2, 2, 2, 2, 2, 2, 1, 2, 2, 2, 2, 2, 2, 1}
 
-// Расширяющая перестановка E (32 → 48 бит)
+// Расширяющая перестановка E (32 → 48 бит); в ядре выполняется сдвигами (см. feistel)
 var Expansion = [48]byte{
 	32, 1, 2, 3, 4, 5,
 	4, 5, 6, 7, 8, 9,
@@ -129,7 +133,67 @@ var PermP = [32]byte{
 	19, 13, 30, 6, 22, 11, 4, 25,
 }
 
-//  Служебные функции для работы с битами
+//  Предвычисленные таблицы ядра
+//
+// Перестановки и S-блоки применяются не побитно, а через таблицы,
+// построенные один раз при загрузке пакета. Блок обрабатывается как
+// 64-битное слово, его половины — как 32-битные; выделений памяти на блок нет.
+
+var (
+	// ipTable, ipInvTable — вклад байта с номером i (0 — старший) и значением v
+	// в результат перестановки IP и IP⁻¹: перестановка блока — OR восьми значений.
+	ipTable, ipInvTable [8][256]uint64
+
+	// spBox — S-блок, совмещённый с перестановкой P: для 6-битного входа
+	// S-блока box — его 4 выходных бита уже на местах после P.
+	spBox [8][64]uint32
+)
+
+func init() {
+	for i := range 8 {
+		for v := range 256 {
+			in := uint64(v) << (56 - 8*i)
+			ipTable[i][v] = permute(in, 64, IP[:])
+			ipInvTable[i][v] = permute(in, 64, IPInv[:])
+		}
+	}
+	for box := range 8 {
+		for v := range 64 {
+			// row: биты 1 и 6 (крайние); col: биты 2-5
+			row := (v>>5)<<1 | v&1
+			col := (v >> 1) & 0x0F
+			s := uint64(Sboxes[box][row][col]) << (28 - 4*box)
+			spBox[box][v] = uint32(permute(s, 32, PermP[:]))
+		}
+	}
+}
+
+// permute применяет таблицу перестановки к младшим width битам in
+// (бит 1 таблицы — старший из них). Результат — в младших len(table) битах.
+// Используется при построении таблиц и для ключа, не для блоков.
+func permute(in uint64, width int, table []byte) uint64 {
+	var out uint64
+	for _, pos := range table {
+		out = out<<1 | (in>>(width-int(pos)))&1
+	}
+	return out
+}
+
+// permuteBlock переставляет 64-битный блок по байтовой таблице (IP или IP⁻¹).
+func permuteBlock(t *[8][256]uint64, b uint64) uint64 {
+	return t[0][b>>56] | t[1][byte(b>>48)] | t[2][byte(b>>40)] | t[3][byte(b>>32)] |
+		t[4][byte(b>>24)] | t[5][byte(b>>16)] | t[6][byte(b>>8)] | t[7][byte(b)]
+}
+
+// rotateLeft28 выполняет циклический сдвиг влево 28-битного значения.
+func rotateLeft28(v uint32, n byte) uint32 {
+	return (v<<n | v>>(28-n)) & 0x0FFFFFFF
+}
+
+//  Побитовые примитивы
+//
+//  Ядро ими не пользуется; они оставлены для внешнего кода, который
+//  работает с таблицами FIPS 46-3 напрямую (нумерация бит с единицы).
 
 // GetBit возвращает бит с 1-based позицией pos из среза байт b.
 func GetBit(b []byte, pos byte) byte {
@@ -159,53 +223,26 @@ func Permute(input []byte, table []byte) []byte {
 // RotateLeft28 выполняет циклический сдвиг влево 28-битного значения,
 // записанного в старших 28 битах 4-байтного big-endian числа.
 func RotateLeft28(b [4]byte, n byte) [4]byte {
-	val := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
-	val28 := val >> 4
-	val28 = ((val28 << n) | (val28 >> (28 - n))) & 0x0FFFFFFF
-	val = val28 << 4
-	return [4]byte{byte(val >> 24), byte(val >> 16), byte(val >> 8), byte(val)}
+	val := rotateLeft28(binary.BigEndian.Uint32(b[:])>>4, n) << 4
+	return [4]byte(binary.BigEndian.AppendUint32(nil, val))
 }
 
 //  Генерация подключей
 
 // GenerateSubkeys строит 16 подключей по 48 бит из 64-битного ключа.
 func GenerateSubkeys(key [8]byte) [16][6]byte {
-	// PC-1: 64 → 56 бит
-	pc1Perm := Permute(key[:], PC1[:])
-
-	// Делим на C (левая) и D (правая) половины по 28 бит
-	var c, d [4]byte
-	copy(c[:], pc1Perm[:4])
-	dBits := (uint32(pc1Perm[3])&0x0F)<<24 |
-		uint32(pc1Perm[4])<<16 |
-		uint32(pc1Perm[5])<<8 |
-		uint32(pc1Perm[6])
-	d[0] = byte(dBits >> 24)
-	d[1] = byte(dBits >> 16)
-	d[2] = byte(dBits >> 8)
-	d[3] = byte(dBits)
+	// PC-1: 64 → 56 бит; C (левая) и D (правая) половины по 28 бит
+	cd := permute(binary.BigEndian.Uint64(key[:]), 64, PC1[:])
+	c, d := uint32(cd>>28), uint32(cd&0x0FFFFFFF)
 
 	var subkeys [16][6]byte
 	for i := 0; i < 16; i++ {
-		c = RotateLeft28(c, KeyShifts[i])
-		d = RotateLeft28(d, KeyShifts[i])
-
-		// Объединяем C и D в 56-битное значение для PC-2
-		cd := make([]byte, 7)
-		cVal := uint32(c[0])<<24 | uint32(c[1])<<16 | uint32(c[2])<<8 | uint32(c[3])
-		dVal := uint32(d[0])<<24 | uint32(d[1])<<16 | uint32(d[2])<<8 | uint32(d[3])
-		combined := (uint64(cVal>>4) << 28) | uint64(dVal>>4)
-		cd[0] = byte(combined >> 48)
-		cd[1] = byte(combined >> 40)
-		cd[2] = byte(combined >> 32)
-		cd[3] = byte(combined >> 24)
-		cd[4] = byte(combined >> 16)
-		cd[5] = byte(combined >> 8)
-		cd[6] = byte(combined)
+		c = rotateLeft28(c, KeyShifts[i])
+		d = rotateLeft28(d, KeyShifts[i])
 
 		// PC-2: 56 → 48 бит
-		sk := Permute(cd, PC2[:])
-		copy(subkeys[i][:], sk[:6])
+		k := permute(uint64(c)<<28|uint64(d), 56, PC2[:])
+		subkeys[i] = [6]byte{byte(k >> 40), byte(k >> 32), byte(k >> 24), byte(k >> 16), byte(k >> 8), byte(k)}
 	}
 	return subkeys
 }
@@ -221,41 +258,31 @@ func ReverseSubkeys(subkeys [16][6]byte) [16][6]byte {
 
 //  Функция Фейстеля f(R, K)
 
-// Feistel вычисляет функцию Фейстеля для половины блока и подключа.
-func Feistel(r [4]byte, subkey [6]byte) [4]byte {
-	// E: 32 → 48 бит
-	expanded := Permute(r[:], Expansion[:])
-
-	// XOR с подключом
-	for i := 0; i < 6; i++ {
-		expanded[i] ^= subkey[i]
-	}
+// feistel вычисляет f(R, K) для 48-битного подключа k.
+// Расширение E не строится целиком: 6 входных бит S-блока box — это биты
+// 4·box … 4·box+5 половины R (нумерация с нуля по модулю 32, бит 32 перед битом 1),
+// то есть старшие 6 бит R, циклически сдвинутой влево на 4·box−1.
+func feistel(r uint32, k uint64) uint32 {
+	return spBox[0][(bits.RotateLeft32(r, -1)>>26^uint32(k>>42))&0x3F] |
+		spBox[1][(bits.RotateLeft32(r, 3)>>26^uint32(k>>36))&0x3F] |
+		spBox[2][(bits.RotateLeft32(r, 7)>>26^uint32(k>>30))&0x3F] |
+		spBox[3][(bits.RotateLeft32(r, 11)>>26^uint32(k>>24))&0x3F] |
+		spBox[4][(bits.RotateLeft32(r, 15)>>26^uint32(k>>18))&0x3F] |
+		spBox[5][(bits.RotateLeft32(r, 19)>>26^uint32(k>>12))&0x3F] |
+		spBox[6][(bits.RotateLeft32(r, 23)>>26^uint32(k>>6))&0x3F] |
+		spBox[7][(bits.RotateLeft32(r, 27)>>26^uint32(k))&0x3F]
+}
 
-	// S-блоки: 48 → 32 бит
-	var sOut [4]byte
-	for box := 0; box < 8; box++ {
-		bitOffset := box * 6
-		var sixBits byte
-		for b := 0; b < 6; b++ {
-			bit := GetBit(expanded, byte(bitOffset+b+1))
-			sixBits = (sixBits << 1) | bit
-		}
-		// row: биты 1 и 6 (крайние); col: биты 2-5
-		row := ((sixBits >> 5) & 1) | (sixBits & 1)
-		col := (sixBits >> 1) & 0x0F
-		sVal := Sboxes[box][row][col]
-		bitBase := box * 4
-		SetBit(sOut[:], bitBase+0, (sVal>>3)&1)
-		SetBit(sOut[:], bitBase+1, (sVal>>2)&1)
-		SetBit(sOut[:], bitBase+2, (sVal>>1)&1)
-		SetBit(sOut[:], bitBase+3, sVal&1)
-	}
+// subkeyWord возвращает 48-битный подключ как число.
+func subkeyWord(k [6]byte) uint64 {
+	return uint64(k[0])<<40 | uint64(k[1])<<32 | uint64(k[2])<<24 | uint64(k[3])<<16 | uint64(k[4])<<8 | uint64(k[5])
+}
 
-	// P-перестановка: 32 → 32 бит
-	pOut := Permute(sOut[:], PermP[:])
-	var result [4]byte
-	copy(result[:], pOut)
-	return result
+// Feistel вычисляет функцию Фейстеля для половины блока и подключа.
+func Feistel(r [4]byte, subkey [6]byte) [4]byte {
+	var out [4]byte
+	binary.BigEndian.PutUint32(out[:], feistel(binary.BigEndian.Uint32(r[:]), subkeyWord(subkey)))
+	return out
 }
 
 //  Шифрование / дешифрование одного 64-битного блока DES
@@ -265,29 +292,17 @@ func Feistel(r [4]byte, subkey [6]byte) [4]byte {
 // для дешифрования — в обратном (см. ReverseSubkeys).
 func DesBlock(block [8]byte, subkeys [16][6]byte) [8]byte {
 	// Начальная перестановка IP
-	ipOut := Permute(block[:], IP[:])
-
-	var l, r [4]byte
-	copy(l[:], ipOut[:4])
-	copy(r[:], ipOut[4:])
+	b := permuteBlock(&ipTable, binary.BigEndian.Uint64(block[:]))
+	l, r := uint32(b>>32), uint32(b)
 
 	// 16 раундов Фейстеля
 	for i := 0; i < 16; i++ {
-		f := Feistel(r, subkeys[i])
-		newR := [4]byte{
-			l[0] ^ f[0], l[1] ^ f[1], l[2] ^ f[2], l[3] ^ f[3],
-		}
-		l = r
-		r = newR
+		l, r = r, l^feistel(r, subkeyWord(subkeys[i]))
 	}
 
 	// Обратная перестановка IP⁻¹ (с обменом L и R)
-	preOut := make([]byte, 8)
-	copy(preOut[:4], r[:])
-	copy(preOut[4:], l[:])
-	result := Permute(preOut, IPInv[:])
 	var out [8]byte
-	copy(out[:], result)
+	binary.BigEndian.PutUint64(out[:], permuteBlock(&ipInvTable, uint64(r)<<32|uint64(l)))
 	return out
 }
 
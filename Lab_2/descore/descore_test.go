@@ -0,0 +1,199 @@
+package descore
+
+import (
+	"crypto/des"
+	"math/rand/v2"
+	"testing"
+)
+
+//  Побитовое ядро — эталон для табличного
+//
+//  Прежняя реализация DES, переписанная по FIPS 46-3 бит за битом через
+//  Permute, GetBit, SetBit и RotateLeft28. Табличное ядро должно давать
+//  те же подключи, значения f(R, K) и блоки.
+
+func bitwiseSubkeys(key [8]byte) [16][6]byte {
+	pc1 := Permute(key[:], PC1[:])
+	// C — биты 1–28, D — биты 29–56; обе половины в старших 28 битах
+	var c, d [4]byte
+	for i := range 28 {
+		SetBit(c[:], i, GetBit(pc1, byte(i+1)))
+		SetBit(d[:], i, GetBit(pc1, byte(i+29)))
+	}
+
+	var subkeys [16][6]byte
+	for i := range 16 {
+		c = RotateLeft28(c, KeyShifts[i])
+		d = RotateLeft28(d, KeyShifts[i])
+		cd := make([]byte, 7)
+		for j := range 28 {
+			SetBit(cd, j, GetBit(c[:], byte(j+1)))
+			SetBit(cd, j+28, GetBit(d[:], byte(j+1)))
+		}
+		copy(subkeys[i][:], Permute(cd, PC2[:]))
+	}
+	return subkeys
+}
+
+func bitwiseFeistel(r [4]byte, subkey [6]byte) [4]byte {
+	expanded := Permute(r[:], Expansion[:])
+	for i := range expanded {
+		expanded[i] ^= subkey[i]
+	}
+	var sOut [4]byte
+	for box := range 8 {
+		var six byte
+		for b := range 6 {
+			six = six<<1 | GetBit(expanded, byte(6*box+b+1))
+		}
+		row := (six>>5)<<1 | six&1
+		col := (six >> 1) & 0x0F
+		v := Sboxes[box][row][col]
+		for b := range 4 {
+			SetBit(sOut[:], 4*box+b, (v>>(3-b))&1)
+		}
+	}
+	return [4]byte(Permute(sOut[:], PermP[:]))
+}
+
+func bitwiseBlock(block [8]byte, subkeys [16][6]byte) [8]byte {
+	ip := Permute(block[:], IP[:])
+	l, r := [4]byte(ip[:4]), [4]byte(ip[4:])
+	for i := range 16 {
+		f := bitwiseFeistel(r, subkeys[i])
+		l, r = r, [4]byte{l[0] ^ f[0], l[1] ^ f[1], l[2] ^ f[2], l[3] ^ f[3]}
+	}
+	return [8]byte(Permute(append(r[:], l[:]...), IPInv[:]))
+}
+
+// TestCoreMatchesBitwise сравнивает табличное ядро с побитовым и с crypto/des.
+func TestCoreMatchesBitwise(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	var key, block [8]byte
+	for range 1000 {
+		for i := range 8 {
+			key[i], block[i] = byte(rng.Uint32()), byte(rng.Uint32())
+		}
+
+		subkeys := GenerateSubkeys(key)
+		if want := bitwiseSubkeys(key); subkeys != want {
+			t.Fatalf("ключ %x: подключи %x, ожидается %x", key, subkeys, want)
+		}
+
+		half := [4]byte(block[:4])
+		if got, want := Feistel(half, subkeys[0]), bitwiseFeistel(half, subkeys[0]); got != want {
+			t.Fatalf("f(%x, %x) = %x, ожидается %x", half, subkeys[0], got, want)
+		}
+
+		got := DesBlock(block, subkeys)
+		if want := bitwiseBlock(block, subkeys); got != want {
+			t.Fatalf("ключ %x, блок %x: %x, побитовое ядро %x", key, block, got, want)
+		}
+		ref, _ := des.NewCipher(key[:])
+		var want [8]byte
+		ref.Encrypt(want[:], block[:])
+		if got != want {
+			t.Fatalf("ключ %x, блок %x: %x, crypto/des %x", key, block, got, want)
+		}
+		if back := DesBlock(got, ReverseSubkeys(subkeys)); back != block {
+			t.Fatalf("ключ %x: дешифрование %x, ожидается %x", key, back, block)
+		}
+	}
+}
+
+// TestDesBlockExample — пошаговый пример DES из учебной литературы
+// (ключ 133457799BBCDFF1, открытый текст 0123456789ABCDEF).
+func TestDesBlockExample(t *testing.T) {
+	want := [8]byte{0x85, 0xe8, 0x13, 0x54, 0x0f, 0x0a, 0xb4, 0x05}
+	if got := DesBlock(benchBlock, GenerateSubkeys(benchKey)); got != want {
+		t.Errorf("шифртекст %x, ожидается %x", got, want)
+	}
+}
+
+func TestRotateLeft28(t *testing.T) {
+	// 28-битное значение 0x8000001 в старших битах: сдвиг на 1 → 0x0000003
+	in := [4]byte{0x80, 0x00, 0x00, 0x10}
+	if got, want := RotateLeft28(in, 1), [4]byte{0x00, 0x00, 0x00, 0x30}; got != want {
+		t.Errorf("RotateLeft28(%x, 1) = %x, ожидается %x", in, got, want)
+	}
+}
+
+//  Бенчмарки
+//
+// Сравнение скорости ядра с crypto/des стандартной библиотеки:
+//
+//	go test -bench . -benchmem
+
+var (
+	benchKey    = [8]byte{0x13, 0x34, 0x57, 0x79, 0x9b, 0xbc, 0xdf, 0xf1}
+	benchKey3   = []byte("0123456789abcdefFEDCBA98")
+	benchBlock  = [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	benchResult [8]byte
+)
+
+// BenchmarkDesBlock — шифрование одного блока одиночным DES.
+func BenchmarkDesBlock(b *testing.B) {
+	b.Run("descore", func(b *testing.B) {
+		subkeys := GenerateSubkeys(benchKey)
+		b.SetBytes(8)
+		b.ReportAllocs()
+		for b.Loop() {
+			benchResult = DesBlock(benchBlock, subkeys)
+		}
+	})
+	b.Run("crypto-des", func(b *testing.B) {
+		c, err := des.NewCipher(benchKey[:])
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(8)
+		b.ReportAllocs()
+		for b.Loop() {
+			c.Encrypt(benchResult[:], benchBlock[:])
+		}
+	})
+}
+
+// BenchmarkTripleDes — шифрование одного блока тройным DES (EDE3).
+func BenchmarkTripleDes(b *testing.B) {
+	b.Run("descore", func(b *testing.B) {
+		c, err := NewCipher(benchKey3)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(8)
+		b.ReportAllocs()
+		for b.Loop() {
+			benchResult = c.EncryptBlock(benchBlock)
+		}
+	})
+	b.Run("crypto-des", func(b *testing.B) {
+		c, err := des.NewTripleDESCipher(benchKey3)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(8)
+		b.ReportAllocs()
+		for b.Loop() {
+			c.Encrypt(benchResult[:], benchBlock[:])
+		}
+	})
+}
+
+// BenchmarkKeySchedule — построение подключей.
+func BenchmarkKeySchedule(b *testing.B) {
+	b.Run("descore", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = GenerateSubkeys(benchKey)
+		}
+	})
+	b.Run("crypto-des", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := des.NewCipher(benchKey[:]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
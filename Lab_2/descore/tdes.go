@@ -0,0 +1,107 @@
+package descore
+
+import "fmt"
+
+//  Тройной DES (EDE, NIST SP 800-67)
+//
+//  Шифрование:    C = E_K3( D_K2( E_K1(P) ) )
+//  Дешифрование:  P = D_K1( E_K2( D_K3(C) ) )
+//
+//  EDE3 — три независимых ключа (24 байта), EDE2 — два ключа (16 байт), K3 = K1.
+//  При K1 = K2 = K3 тройной DES совпадает с одиночным.
+
+// SplitTripleKey делит ключ тройного DES на K1, K2, K3.
+// 16 байт — EDE2 (K3 = K1), 24 байта — EDE3.
+func SplitTripleKey(key []byte) ([3][8]byte, error) {
+	var keys [3][8]byte
+	switch len(key) {
+	case 16:
+		copy(keys[0][:], key[:8])
+		copy(keys[1][:], key[8:16])
+		keys[2] = keys[0]
+	case 24:
+		copy(keys[0][:], key[:8])
+		copy(keys[1][:], key[8:16])
+		copy(keys[2][:], key[16:24])
+	default:
+		return keys, fmt.Errorf("ключ тройного DES должен быть 16 (EDE2) или 24 (EDE3) байта, получено %d", len(key))
+	}
+	return keys, nil
+}
+
+// TripleSubkeys — подключи K1, K2, K3 тройного DES (в прямом порядке).
+type TripleSubkeys [3][16][6]byte
+
+// GenerateTripleSubkeys строит подключи трёх ключей тройного DES.
+func GenerateTripleSubkeys(keys [3][8]byte) TripleSubkeys {
+	var sk TripleSubkeys
+	for i, k := range keys {
+		sk[i] = GenerateSubkeys(k)
+	}
+	return sk
+}
+
+// TripleDesEncrypt шифрует блок тройным DES: E_K3(D_K2(E_K1(P))).
+func TripleDesEncrypt(block [8]byte, sk TripleSubkeys) [8]byte {
+	block = DesBlock(block, sk[0])
+	block = DesBlock(block, ReverseSubkeys(sk[1]))
+	return DesBlock(block, sk[2])
+}
+
+// TripleDesDecrypt дешифрует блок тройным DES: D_K1(E_K2(D_K3(C))).
+func TripleDesDecrypt(block [8]byte, sk TripleSubkeys) [8]byte {
+	block = DesBlock(block, ReverseSubkeys(sk[2]))
+	block = DesBlock(block, sk[1])
+	return DesBlock(block, ReverseSubkeys(sk[0]))
+}
+
+//  Шифр для режимов: одиночный или тройной DES по длине ключа
+
+// Cipher — блочный шифр DES с готовыми подключами: одиночный DES (ключ 8 байт)
+// или тройной DES (16 байт — EDE2, 24 байта — EDE3). Шифрование и
+// дешифрование — последовательность проходов DES с прямыми или обратными подключами.
+type Cipher struct {
+	name     string
+	enc, dec [][16][6]byte
+}
+
+// NewCipher выбирает одиночный или тройной DES по длине ключа и строит подключи.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) == 8 {
+		sk := GenerateSubkeys([8]byte(key))
+		return &Cipher{name: "DES", enc: [][16][6]byte{sk}, dec: [][16][6]byte{ReverseSubkeys(sk)}}, nil
+	}
+	keys, err := SplitTripleKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("ключ должен быть 8 байт (DES), 16 (3DES-EDE2) или 24 (3DES-EDE3), получено %d", len(key))
+	}
+	sk := GenerateTripleSubkeys(keys)
+	name := "3DES-EDE3"
+	if len(key) == 16 {
+		name = "3DES-EDE2"
+	}
+	return &Cipher{
+		name: name,
+		enc:  [][16][6]byte{sk[0], ReverseSubkeys(sk[1]), sk[2]},
+		dec:  [][16][6]byte{ReverseSubkeys(sk[2]), sk[1], ReverseSubkeys(sk[0])},
+	}, nil
+}
+
+// String возвращает название алгоритма: DES, 3DES-EDE2 или 3DES-EDE3.
+func (c *Cipher) String() string { return c.name }
+
+// EncryptBlock шифрует один 8-байтный блок.
+func (c *Cipher) EncryptBlock(block [8]byte) [8]byte {
+	for _, sk := range c.enc {
+		block = DesBlock(block, sk)
+	}
+	return block
+}
+
+// DecryptBlock дешифрует один 8-байтный блок.
+func (c *Cipher) DecryptBlock(block [8]byte) [8]byte {
+	for _, sk := range c.dec {
+		block = DesBlock(block, sk)
+	}
+	return block
+}
@@ -0,0 +1,92 @@
+package descore
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Контрольные примеры тройного DES. EDE3 — пример из NIST SP 800-67
+// (ключи 0123456789ABCDEF, 23456789ABCDEF01, 456789ABCDEF0123, открытый текст
+// «The qufck brown fox jump»), EDE2 — тот же открытый текст с K3 = K1,
+// шифртекст получен des.NewTripleDESCipher.
+var tripleVectors = []struct {
+	name, key, ct string
+}{
+	{"3DES-EDE3", "0123456789abcdef23456789abcdef01456789abcdef0123",
+		"a826fd8ce53b855fcce21c8112256fe668d5c05dd9b6b900"},
+	{"3DES-EDE2", "0123456789abcdef23456789abcdef01",
+		"c44862f70cf2fbdc9077d0909fa91b884cabd61fc58e0cbb"},
+}
+
+const triplePlaintext = "The qufck brown fox jump"
+
+func TestTripleDESVectors(t *testing.T) {
+	for _, v := range tripleVectors {
+		key, _ := hex.DecodeString(v.key)
+		want, _ := hex.DecodeString(v.ct)
+
+		c, err := NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.String() != v.name {
+			t.Errorf("ключ %d байт: алгоритм %s, ожидается %s", len(key), c, v.name)
+		}
+
+		keys, err := SplitTripleKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk := GenerateTripleSubkeys(keys)
+
+		for i := 0; i < len(triplePlaintext); i += 8 {
+			pt := [8]byte([]byte(triplePlaintext[i : i+8]))
+			ct := [8]byte(want[i : i+8])
+			if got := c.EncryptBlock(pt); got != ct {
+				t.Errorf("%s, блок %d: шифртекст %x, ожидается %x", v.name, i/8, got, ct)
+			}
+			if got := TripleDesEncrypt(pt, sk); got != ct {
+				t.Errorf("%s, блок %d: TripleDesEncrypt %x, ожидается %x", v.name, i/8, got, ct)
+			}
+			if got := c.DecryptBlock(ct); got != pt {
+				t.Errorf("%s, блок %d: дешифрование %q, ожидается %q", v.name, i/8, got, pt)
+			}
+			if got := TripleDesDecrypt(ct, sk); got != pt {
+				t.Errorf("%s, блок %d: TripleDesDecrypt %q, ожидается %q", v.name, i/8, got, pt)
+			}
+		}
+	}
+}
+
+// TestTripleDESDegenerates проверяет, что при K1 = K2 = K3 тройной DES
+// совпадает с одиночным.
+func TestTripleDESDegenerates(t *testing.T) {
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9b, 0xbc, 0xdf, 0xf1}
+	block := [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+
+	single, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{2, 3} {
+		var triple []byte
+		for range n {
+			triple = append(triple, key...)
+		}
+		c, err := NewCipher(triple)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := c.EncryptBlock(block), single.EncryptBlock(block); got != want {
+			t.Errorf("%s с равными ключами: %x, ожидается %x", c, got, want)
+		}
+	}
+}
+
+func TestNewCipherKeyLength(t *testing.T) {
+	for _, n := range []int{0, 7, 9, 15, 17, 23, 25, 32} {
+		if _, err := NewCipher(make([]byte, n)); err == nil {
+			t.Errorf("ключ %d байт принят", n)
+		}
+	}
+}